@@ -7,6 +7,8 @@ var (
 	MethodNotAllowed = ErrorCode{"MethodNotAllow", 405}
 	Conflict         = ErrorCode{"Conflict", 409}
 
+	UnsupportedMediaType = ErrorCode{"UnsupportedMediaType", 415}
+
 	DuplicateResource  = ErrorCode{"DuplicateResource", 422}
 	DeleteParent       = ErrorCode{"DeleteParent", 422}
 	InvalidFormat      = ErrorCode{"InvalidFormat", 422}
@@ -23,9 +25,19 @@ var (
 	InvalidAction      = ErrorCode{"InvalidAction", 422}
 	InvalidBodyContent = ErrorCode{"InvalidBodyContent", 422}
 	InvalidType        = ErrorCode{"InvalidType", 422}
+	PatternMismatch    = ErrorCode{"PatternMismatch", 422}
+	InvalidIP          = ErrorCode{"InvalidIP", 422}
+	InvalidCIDR        = ErrorCode{"InvalidCIDR", 422}
+	InvalidEmail       = ErrorCode{"InvalidEmail", 422}
+	InvalidURL         = ErrorCode{"InvalidURL", 422}
+	InvalidMAC         = ErrorCode{"InvalidMAC", 422}
+	InvalidDuration    = ErrorCode{"InvalidDuration", 422}
+	ComparisonFailed   = ErrorCode{"ComparisonFailed", 422}
+	MutuallyExclusive  = ErrorCode{"MutuallyExclusive", 422}
 
 	ServerError        = ErrorCode{"ServerError", 500}
 	ClusterUnavailable = ErrorCode{"ClusterUnavailable", 503}
+	ServiceUnavailable = ErrorCode{"ServiceUnavailable", 503}
 )
 
 type ErrorCode struct {
@@ -35,7 +47,15 @@ type ErrorCode struct {
 
 type APIError struct {
 	ErrorCode `json:",inline"`
-	Type      string `json:"type,omitempty"`
+	Type      string        `json:"type,omitempty"`
+	Message   string        `json:"message,omitempty"`
+	Details   []ErrorDetail `json:"details,omitempty"`
+}
+
+//error of one field in request body, path is the json pointer of the field
+type ErrorDetail struct {
+	ErrorCode `json:",inline"`
+	Path      string `json:"path"`
 	Message   string `json:"message,omitempty"`
 }
 
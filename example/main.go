@@ -209,7 +209,7 @@ func (h *clusterHandler) Create(ctx *resource.Context) (resource.Resource, *gore
 }
 
 func (h *clusterHandler) List(ctx *resource.Context) (interface{}, *goresterr.APIError) {
-	return h.clusters.GetClusters(), nil
+	return resource.ApplyFilters(ctx, h.clusters.GetClusters())
 }
 
 func (h *clusterHandler) Get(ctx *resource.Context) (resource.Resource, *goresterr.APIError) {
@@ -0,0 +1,84 @@
+package gorest
+
+import (
+	"fmt"
+	"path"
+
+	goresterr "github.com/zdnscloud/gorest/error"
+	"github.com/zdnscloud/gorest/resource"
+)
+
+//embed the child collections specified by expand paths into resource
+func expandResource(ctx *resource.Context, r resource.Resource, paths [][]string) (resource.Resource, *goresterr.APIError) {
+	if len(paths) == 0 {
+		return r, nil
+	}
+
+	var names []string
+	subPaths := make(map[string][][]string)
+	for _, p := range paths {
+		if _, ok := subPaths[p[0]]; ok == false {
+			names = append(names, p[0])
+			subPaths[p[0]] = nil
+		}
+		if len(p) > 1 {
+			subPaths[p[0]] = append(subPaths[p[0]], p[1:])
+		}
+	}
+
+	expanded := &resource.ExpandedResource{
+		Resource: r,
+		Children: make(map[string]*resource.ResourceCollection),
+	}
+	for _, name := range names {
+		rc, err := listChildResources(ctx, r, name, subPaths[name])
+		if err != nil {
+			return nil, err
+		}
+		expanded.Children[name] = rc
+	}
+	return expanded, nil
+}
+
+func listChildResources(ctx *resource.Context, parent resource.Resource, name string, paths [][]string) (*resource.ResourceCollection, *goresterr.APIError) {
+	child, err := parent.GetSchema().CreateChildResource(parent, name)
+	if err != nil {
+		return nil, goresterr.NewAPIError(goresterr.InvalidFormat, fmt.Sprintf("expand failed:%s", err.Error()))
+	}
+
+	if kind, ok := child.(resource.ResourceKind); ok && kind.SupportExpand() == false {
+		return nil, goresterr.NewAPIError(goresterr.InvalidFormat, fmt.Sprintf("%s doesn't support expand", name))
+	}
+
+	schema := child.GetSchema()
+	handler := schema.GetHandler().GetListHandler()
+	if handler == nil {
+		return nil, goresterr.NewAPIError(goresterr.InvalidFormat, fmt.Sprintf("%s doesn't support list", name))
+	}
+
+	data, apiErr := handler(ctx.NewChildContext(child))
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	rc, err := resource.NewResourceCollection(child, data)
+	if err != nil {
+		return nil, goresterr.NewAPIError(goresterr.ServerError, err.Error())
+	}
+
+	httpSchemeAndHost := path.Join(ctx.Request.URL.Scheme, ctx.Request.URL.Host)
+	if err := schema.AddLinksToResourceCollection(rc, httpSchemeAndHost); err != nil {
+		return nil, goresterr.NewAPIError(goresterr.ServerError, fmt.Sprintf("generate links failed:%s", err.Error()))
+	}
+
+	if len(paths) > 0 {
+		for i, r := range rc.Resources {
+			r.SetSchema(schema)
+			r.SetParent(parent)
+			if rc.Resources[i], apiErr = expandResource(ctx, r, paths); apiErr != nil {
+				return nil, apiErr
+			}
+		}
+	}
+	return rc, nil
+}
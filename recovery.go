@@ -0,0 +1,80 @@
+package gorest
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/gorilla/websocket"
+	"github.com/zdnscloud/cement/uuid"
+	goresterr "github.com/zdnscloud/gorest/error"
+	"github.com/zdnscloud/gorest/resource"
+)
+
+const RequestIDKey = "X-Request-Id"
+
+//logger of github.com/zdnscloud/cement/log could be used directly
+type Logger interface {
+	Error(fmt string, args ...interface{}) error
+}
+
+type stdLogger struct{}
+
+func (l stdLogger) Error(format string, args ...interface{}) error {
+	log.Printf("[ERROR] "+format, args...)
+	return nil
+}
+
+//panic of async action is only logged since its request has finished,
+//nil is returned if recovery is disabled
+func (s *Server) actionPanicHandler(ctx *resource.Context) func(interface{}) {
+	if s.recovery == false {
+		return nil
+	}
+	return func(r interface{}) {
+		s.logger.Error("action %s panic: %v\n%s", ctx.Resource.GetAction().Name, r, debug.Stack())
+	}
+}
+
+//convert the panic into server error, the request id is returned to
+//client so the log could be found
+func (s *Server) recoverPanic(rw http.ResponseWriter, req *http.Request) {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	//net/http uses it to abort the response silently
+	if r == http.ErrAbortHandler {
+		panic(r)
+	}
+
+	id := s.logPanic(req, r)
+	rw.Header().Set(RequestIDKey, id)
+	err := goresterr.NewAPIError(goresterr.ServerError, fmt.Sprintf("internal server error, request id %s", id))
+	WriteResponse(rw, err.Status, err)
+}
+
+//response can't be written to hijacked connection, so the error is
+//sent as the last event of websocket
+func (s *Server) recoverWebSocketPanic(conn *websocket.Conn, req *http.Request) {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	id := s.logPanic(req, r)
+	err := goresterr.NewAPIError(goresterr.ServerError, fmt.Sprintf("internal server error, request id %s", id))
+	closeWebSocket(conn, websocket.CloseInternalServerErr, err)
+}
+
+//return the request id which is logged with the panic
+func (s *Server) logPanic(req *http.Request, r interface{}) string {
+	id := req.Header.Get(RequestIDKey)
+	if id == "" {
+		id, _ = uuid.Gen()
+	}
+	s.logger.Error("%s %s with request id %s panic: %v\n%s", req.Method, req.URL.Path, id, r, debug.Stack())
+	return id
+}
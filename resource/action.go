@@ -1,7 +1,46 @@
 package resource
 
+import (
+	"fmt"
+	"reflect"
+)
+
+const ActionQueryKey = "action"
+
 type Action struct {
 	Name   string      `json:"name"`
 	Input  interface{} `json:"input,omitempty"`
 	Output interface{} `json:"output,omitempty"`
+	//async action runs as a task, request returns once the task is created
+	Async bool `json:"async,omitempty"`
+}
+
+type Progress struct {
+	Percent int    `json:"percent"`
+	Message string `json:"message,omitempty"`
+}
+
+//result should have the same type as output, pointer and the value
+//it points to are treated as the same type, nil result is always valid
+func (a *Action) CheckOutput(result interface{}) error {
+	if a.Output == nil || result == nil {
+		return nil
+	}
+
+	expect := reflect.TypeOf(a.Output)
+	if expect.Kind() == reflect.Ptr {
+		expect = expect.Elem()
+	}
+	got := reflect.TypeOf(result)
+	if got.Kind() == reflect.Ptr {
+		if reflect.ValueOf(result).IsNil() {
+			return nil
+		}
+		got = got.Elem()
+	}
+
+	if got != expect {
+		return fmt.Errorf("action %s returns %v which isn't output type %v", a.Name, got, expect)
+	}
+	return nil
 }
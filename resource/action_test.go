@@ -0,0 +1,28 @@
+package resource
+
+import (
+	"testing"
+
+	ut "github.com/zdnscloud/cement/unittest"
+)
+
+type actionOutput struct {
+	Name string `json:"name"`
+}
+
+func TestActionCheckOutput(t *testing.T) {
+	action := &Action{
+		Name:   "login",
+		Output: &actionOutput{},
+	}
+	var nilOutput *actionOutput
+	for _, result := range []interface{}{nil, nilOutput, actionOutput{}, &actionOutput{Name: "a"}} {
+		ut.Assert(t, action.CheckOutput(result) == nil, "%v should be valid output", result)
+	}
+	for _, result := range []interface{}{"a", []actionOutput{}, &Action{}} {
+		ut.Assert(t, action.CheckOutput(result) != nil, "%v should be invalid output", result)
+	}
+
+	action.Output = nil
+	ut.Assert(t, action.CheckOutput("a") == nil, "")
+}
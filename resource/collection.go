@@ -1,6 +1,7 @@
 package resource
 
 import (
+	"encoding/json"
 	"fmt"
 	"reflect"
 )
@@ -10,6 +11,7 @@ type ResourceCollection struct {
 	ResourceType string                            `json:"resourceType,omitempty"`
 	Links        map[ResourceLinkType]ResourceLink `json:"links,omitempty"`
 	Resources    []Resource                        `json:"data"`
+	Pagination   *Pagination                       `json:"pagination,omitempty"`
 
 	collection Resource `json:"-"`
 }
@@ -91,6 +93,49 @@ func (rc *ResourceCollection) GetCollection() Resource {
 	return rc.collection
 }
 
+//sort should be set before pagination
+func (rc *ResourceCollection) SetSort(s *Sort) error {
+	return s.apply(rc.Resources)
+}
+
+//only keep the resources in current page
+func (rc *ResourceCollection) SetPagination(p *Pagination) {
+	rc.Resources = p.apply(rc.Resources)
+	rc.Pagination = p
+}
+
+func (rc *ResourceCollection) GetPagination() *Pagination {
+	return rc.Pagination
+}
+
 func (rc *ResourceCollection) GetResources() []Resource {
 	return rc.Resources
 }
+
+//writeonly fields of resources are removed
+func (rc ResourceCollection) MarshalJSON() ([]byte, error) {
+	type collection ResourceCollection
+	if len(rc.Resources) == 0 {
+		return json.Marshal(collection(rc))
+	}
+
+	//resources in collection have same kind
+	fields := writeOnlyFields(reflect.TypeOf(rc.Resources[0]))
+	if fields == nil {
+		return json.Marshal(collection(rc))
+	}
+
+	resources := make([]map[string]interface{}, 0, len(rc.Resources))
+	for _, r := range rc.Resources {
+		obj, err := toJsonMap(r)
+		if err != nil {
+			return nil, err
+		}
+		fields.HideWriteOnlyFields(obj)
+		resources = append(resources, obj)
+	}
+	return json.Marshal(struct {
+		collection
+		Resources []map[string]interface{} `json:"data"`
+	}{collection(rc), resources})
+}
@@ -2,6 +2,7 @@ package resource
 
 import (
 	"encoding/json"
+	"net/url"
 	"testing"
 
 	ut "github.com/zdnscloud/cement/unittest"
@@ -25,3 +26,104 @@ func TestCollectionToJson(t *testing.T) {
 	d2, _ := json.Marshal(rs2)
 	ut.Equal(t, string(d), string(d2))
 }
+
+func TestCollectionPagination(t *testing.T) {
+	var resources []*dumbResource
+	for i := 0; i < 5; i++ {
+		resources = append(resources, &dumbResource{Number: i})
+	}
+
+	collection := &dumbResource{}
+	collection.SetType(DefaultKindName(collection))
+	u, _ := url.Parse("/apis/testing/v1/dumbresources?limit=2&name=x")
+	p, err := genPagination(u)
+	ut.Assert(t, err == nil, "")
+	rs, _ := NewResourceCollection(collection, resources)
+	rs.SetPagination(p)
+	ut.Equal(t, len(rs.Resources), 2)
+	ut.Equal(t, rs.Resources[0].(*dumbResource).Number, 0)
+	ut.Equal(t, p.Total, 5)
+	ut.Assert(t, p.PrevPageQuery() == nil, "")
+	next := p.NextPageQuery()
+	ut.Equal(t, next.Get("name"), "x")
+	ut.Equal(t, next.Get(LimitQueryKey), "2")
+
+	u.RawQuery = next.Encode()
+	p, _ = genPagination(u)
+	rs, _ = NewResourceCollection(collection, resources)
+	rs.SetPagination(p)
+	ut.Equal(t, rs.Resources[0].(*dumbResource).Number, 2)
+	ut.Equal(t, p.PrevPageQuery().Get(ContinueQueryKey), "")
+
+	u.RawQuery = p.NextPageQuery().Encode()
+	p, _ = genPagination(u)
+	rs, _ = NewResourceCollection(collection, resources)
+	rs.SetPagination(p)
+	ut.Equal(t, len(rs.Resources), 1)
+	ut.Equal(t, rs.Resources[0].(*dumbResource).Number, 4)
+	ut.Assert(t, p.NextPageQuery() == nil, "")
+	ut.Equal(t, p.PrevPageQuery().Get(ContinueQueryKey), encodeContinueToken(2))
+
+	//handler has paged the resources
+	p, _ = genPagination(u)
+	p.SetTotal(10)
+	rs, _ = NewResourceCollection(collection, resources[:2])
+	rs.SetPagination(p)
+	ut.Equal(t, len(rs.Resources), 2)
+	ut.Equal(t, p.Continue, encodeContinueToken(6))
+
+	for _, query := range []string{"limit=0", "limit=a", "continue=MQ", "limit=1&continue=xx"} {
+		u.RawQuery = query
+		_, err := genPagination(u)
+		ut.Assert(t, err != nil, "query %s should be invalid", query)
+	}
+}
+
+type sortResource struct {
+	ResourceBase `json:",inline"`
+	Name         string   `json:"name"`
+	Count        int      `json:"count"`
+	Tags         []string `json:"tags"`
+}
+
+func TestCollectionSort(t *testing.T) {
+	collection := &sortResource{}
+	collection.SetType(DefaultKindName(collection))
+	resources := []*sortResource{
+		&sortResource{Name: "b", Count: 1},
+		&sortResource{Name: "a", Count: 2},
+		&sortResource{Name: "c", Count: 1},
+	}
+
+	cases := []struct {
+		query string
+		names []string
+	}{
+		{"sort=name", []string{"a", "b", "c"}},
+		{"sort=name&order=desc", []string{"c", "b", "a"}},
+		{"sort=count,name&order=asc,desc", []string{"c", "b", "a"}},
+		{"sort=count,name", []string{"b", "c", "a"}},
+		{"sort=creationTimestamp", []string{"b", "a", "c"}},
+	}
+	for _, tc := range cases {
+		u, _ := url.Parse("/apis/testing/v1/sortresources?" + tc.query)
+		s, err := genSort(u, collection)
+		ut.Assert(t, err == nil, "")
+		rs, _ := NewResourceCollection(collection, resources)
+		ut.Assert(t, rs.SetSort(s) == nil, "")
+		var names []string
+		for _, r := range rs.Resources {
+			names = append(names, r.(*sortResource).Name)
+		}
+		ut.Equal(t, names, tc.names)
+	}
+
+	for _, query := range []string{"sort=unknown", "order=asc", "sort=name&order=up", "sort=name,count&order=asc,desc,asc", "sort=tags", "sort=links"} {
+		u, _ := url.Parse("/apis/testing/v1/sortresources?" + query)
+		_, err := genSort(u, collection)
+		ut.Assert(t, err != nil, "query %s should be invalid", query)
+	}
+
+	u, _ := url.Parse("/apis/testing/v1/sortresources?sort=name&name_prefix=a&action=run")
+	ut.Equal(t, genFilters(u), []Filter{Filter{Name: "name", Modifier: Prefix, Values: []string{"a"}}})
+}
@@ -1,6 +1,7 @@
 package resource
 
 import (
+	"context"
 	"net/http"
 	"net/url"
 	"strings"
@@ -24,13 +25,19 @@ const (
 )
 
 type Context struct {
-	Schemas  SchemaManager
-	Request  *http.Request
-	Response http.ResponseWriter
-	Resource Resource
-	Method   string
-	params   map[string]interface{}
-	filters  []Filter
+	Schemas    SchemaManager
+	Request    *http.Request
+	Response   http.ResponseWriter
+	Resource   Resource
+	Method     string
+	params     map[string]interface{}
+	filters    []Filter
+	pagination *Pagination
+	sort       *Sort
+	selector   *FieldSelector
+	expands    [][]string
+	watch      bool
+	reporter   func(Progress)
 }
 
 type Filter struct {
@@ -47,15 +54,53 @@ func NewContext(resp http.ResponseWriter, req *http.Request, schemas SchemaManag
 		return nil, err
 	}
 
+	pagination, err_ := genPagination(req.URL)
+	if err_ != nil {
+		return nil, error.NewAPIError(error.InvalidFormat, err_.Error())
+	}
+
+	sort, err_ := genSort(req.URL, r)
+	if err_ != nil {
+		return nil, error.NewAPIError(error.InvalidFormat, err_.Error())
+	}
+
+	selector, err_ := genFieldSelector(req.URL, r)
+	if err_ != nil {
+		return nil, error.NewAPIError(error.InvalidFormat, err_.Error())
+	}
+
+	expands, err_ := genExpandPaths(req.URL)
+	if err_ != nil {
+		return nil, error.NewAPIError(error.InvalidFormat, err_.Error())
+	}
+
+	return &Context{
+		Request:    req,
+		Response:   resp,
+		Resource:   r,
+		Schemas:    schemas,
+		Method:     req.Method,
+		params:     make(map[string]interface{}),
+		filters:    genFilters(req.URL),
+		pagination: pagination,
+		sort:       sort,
+		selector:   selector,
+		expands:    expands,
+		watch:      req.URL.Query().Get(WatchQueryKey) == "true",
+	}, nil
+}
+
+//context of the request whose resource can't be created, it's passed to
+//the after handlers with the error response, and its resource is nil
+func NewEmptyContext(resp http.ResponseWriter, req *http.Request, schemas SchemaManager) *Context {
 	return &Context{
 		Request:  req,
 		Response: resp,
-		Resource: r,
 		Schemas:  schemas,
 		Method:   req.Method,
 		params:   make(map[string]interface{}),
-		filters:  genFilters(req.URL),
-	}, nil
+		filters:  make([]Filter, 0),
+	}
 }
 
 func (ctx *Context) Set(key string, value interface{}) {
@@ -71,9 +116,95 @@ func (ctx *Context) GetFilters() []Filter {
 	return ctx.filters
 }
 
+//return nil if client doesn't ask for pagination
+func (ctx *Context) GetPagination() *Pagination {
+	return ctx.pagination
+}
+
+//return nil if client doesn't ask for sort
+func (ctx *Context) GetSort() *Sort {
+	return ctx.sort
+}
+
+//return nil if client doesn't select fields
+func (ctx *Context) GetFieldSelector() *FieldSelector {
+	return ctx.selector
+}
+
+//action handler reports its progress, it's ignored if
+//the client doesn't care about the progress
+func (ctx *Context) ReportProgress(percent int, message string) {
+	if ctx.reporter != nil {
+		ctx.reporter(Progress{
+			Percent: percent,
+			Message: message,
+		})
+	}
+}
+
+func (ctx *Context) SetProgressReporter(reporter func(Progress)) {
+	ctx.reporter = reporter
+}
+
+//client asks for the change events of the collection
+func (ctx *Context) IsWatch() bool {
+	return ctx.watch
+}
+
+//child resources to embed into the returned resource
+func (ctx *Context) GetExpandPaths() [][]string {
+	return ctx.expands
+}
+
+//context to call the handler of child resource, the request and
+//params are shared, filter, sort and pagination aren't inherited
+func (ctx *Context) NewChildContext(child Resource) *Context {
+	return &Context{
+		Request:  ctx.Request,
+		Response: ctx.Response,
+		Resource: child,
+		Schemas:  ctx.Schemas,
+		Method:   http.MethodGet,
+		params:   ctx.params,
+		filters:  make([]Filter, 0),
+	}
+}
+
+//context to run async action in background, the request is bound to
+//c instead of the connection, params are copied since the handler of the
+//request may still use them, response is unavailable once request finished
+func (ctx *Context) newTaskContext(c context.Context, reporter func(Progress)) *Context {
+	params := make(map[string]interface{}, len(ctx.params))
+	for k, v := range ctx.params {
+		params[k] = v
+	}
+	taskCtx := *ctx
+	taskCtx.Request = ctx.Request.WithContext(c)
+	taskCtx.Response = nil
+	taskCtx.params = params
+	taskCtx.reporter = reporter
+	return &taskCtx
+}
+
+//query keys which have special meaning, and shouldn't be treated as filter
+var reservedQueryKeys = map[string]struct{}{
+	LimitQueryKey:    struct{}{},
+	ContinueQueryKey: struct{}{},
+	SortQueryKey:     struct{}{},
+	OrderQueryKey:    struct{}{},
+	FieldsQueryKey:   struct{}{},
+	ExcludeQueryKey:  struct{}{},
+	ExpandQueryKey:   struct{}{},
+	WatchQueryKey:    struct{}{},
+	ActionQueryKey:   struct{}{},
+}
+
 func genFilters(url *url.URL) []Filter {
 	filters := make([]Filter, 0)
 	for k, v := range url.Query() {
+		if _, ok := reservedQueryKeys[k]; ok {
+			continue
+		}
 		filter := Filter{
 			Name:     k,
 			Modifier: Eq,
@@ -0,0 +1,58 @@
+package resource
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+const (
+	ExpandQueryKey = "expand"
+	//expand=namespaces.deployments has depth 2
+	MaxExpandDepth = 3
+)
+
+//expand=nodes,namespaces.deployments will be parsed into
+//[[nodes] [namespaces deployments]], name is child resource name
+func genExpandPaths(url *url.URL) ([][]string, error) {
+	expandStr := url.Query().Get(ExpandQueryKey)
+	if expandStr == "" {
+		return nil, nil
+	}
+
+	var paths [][]string
+	for _, p := range strings.Split(expandStr, ",") {
+		path := strings.Split(p, ".")
+		if len(path) > MaxExpandDepth {
+			return nil, fmt.Errorf("expand %s exceed max depth %d", p, MaxExpandDepth)
+		}
+		for _, name := range path {
+			if name == "" {
+				return nil, fmt.Errorf("expand %s has empty resource name", p)
+			}
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+//resource with child collections embedded, each collection
+//is marshalled as a field named with child resource name
+type ExpandedResource struct {
+	Resource
+	Children map[string]*ResourceCollection
+}
+
+func (r *ExpandedResource) MarshalJSON() ([]byte, error) {
+	obj, err := toJsonMap(r.Resource)
+	if err != nil {
+		return nil, err
+	}
+	hideWriteOnlyFields(obj, reflect.TypeOf(r.Resource))
+	for name, rc := range r.Children {
+		obj[name] = rc
+	}
+	return json.Marshal(obj)
+}
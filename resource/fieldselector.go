@@ -0,0 +1,138 @@
+package resource
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/zdnscloud/gorest/util"
+)
+
+const (
+	FieldsQueryKey  = "fields"
+	ExcludeQueryKey = "exclude"
+)
+
+//fields which are always returned to client
+var mandatoryFields = []string{"id", "type", "links"}
+
+//select the fields of resource returned to client
+//fields=name,nodes or exclude=nodes, field name is json name
+type FieldSelector struct {
+	Include []string
+	Exclude []string
+}
+
+func genFieldSelector(url *url.URL, r Resource) (*FieldSelector, error) {
+	query := url.Query()
+	includeStr := query.Get(FieldsQueryKey)
+	excludeStr := query.Get(ExcludeQueryKey)
+	if includeStr == "" && excludeStr == "" {
+		return nil, nil
+	}
+
+	fields := util.JsonFieldIndexes(reflect.TypeOf(r))
+	selector := &FieldSelector{}
+	var err error
+	if includeStr != "" {
+		if selector.Include, err = parseSelectedFields(includeStr, fields); err != nil {
+			return nil, err
+		}
+	}
+	if excludeStr != "" {
+		if selector.Exclude, err = parseSelectedFields(excludeStr, fields); err != nil {
+			return nil, err
+		}
+	}
+	return selector, nil
+}
+
+func parseSelectedFields(s string, fields map[string][]int) ([]string, error) {
+	names := strings.Split(s, ",")
+	for _, name := range names {
+		if _, ok := fields[name]; ok == false {
+			return nil, fmt.Errorf("unknown field %s", name)
+		}
+	}
+	return names, nil
+}
+
+//result other than resource or resource collection is returned directly
+func (s *FieldSelector) Apply(result interface{}) (interface{}, error) {
+	switch r := result.(type) {
+	case *ExpandedResource:
+		obj, err := s.Apply(r.Resource)
+		if err != nil {
+			return nil, err
+		}
+		for name, rc := range r.Children {
+			obj.(map[string]interface{})[name] = rc
+		}
+		return obj, nil
+	case Resource:
+		if isNil(result) {
+			return result, nil
+		}
+		obj, err := toJsonMap(result)
+		if err != nil {
+			return nil, err
+		}
+		hideWriteOnlyFields(obj, reflect.TypeOf(r))
+		s.trim(obj)
+		return obj, nil
+	case *ResourceCollection:
+		obj, err := toJsonMap(result)
+		if err != nil {
+			return nil, err
+		}
+		if data, ok := obj["data"].([]interface{}); ok {
+			for _, elem := range data {
+				if r, ok := elem.(map[string]interface{}); ok {
+					s.trim(r)
+				}
+			}
+		}
+		return obj, nil
+	default:
+		return result, nil
+	}
+}
+
+func (s *FieldSelector) trim(obj map[string]interface{}) {
+	for name := range obj {
+		if isSelected(mandatoryFields, name) {
+			continue
+		}
+		if (len(s.Include) > 0 && !isSelected(s.Include, name)) || isSelected(s.Exclude, name) {
+			delete(obj, name)
+		}
+	}
+}
+
+func isSelected(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+func isNil(i interface{}) bool {
+	v := reflect.ValueOf(i)
+	return i == nil || (v.Kind() == reflect.Ptr && v.IsNil())
+}
+
+func toJsonMap(i interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(i)
+	if err != nil {
+		return nil, err
+	}
+	obj := make(map[string]interface{})
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
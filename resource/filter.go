@@ -0,0 +1,249 @@
+package resource
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	goresterr "github.com/zdnscloud/gorest/error"
+	"github.com/zdnscloud/gorest/util"
+)
+
+//filter the resources returned by list handler with the filters in url,
+//resources should be a slice of resource pointer, a new slice with the
+//same type is returned
+func ApplyFilters(ctx *Context, resources interface{}) (interface{}, *goresterr.APIError) {
+	if resources == nil || len(ctx.GetFilters()) == 0 {
+		return resources, nil
+	}
+
+	v := reflect.ValueOf(resources)
+	if v.Kind() != reflect.Slice {
+		return nil, goresterr.NewAPIError(goresterr.ServerError, fmt.Sprintf("filter apply to non-slice type %v", v.Kind()))
+	}
+
+	fields := filterFieldIndexes(reflect.TypeOf(ctx.Resource))
+	var matchers []*filterMatcher
+	for _, filter := range ctx.GetFilters() {
+		m, err := newFilterMatcher(ctx.Resource, fields, filter)
+		if err != nil {
+			return nil, goresterr.NewAPIError(goresterr.InvalidFormat, err.Error())
+		}
+		matchers = append(matchers, m)
+	}
+
+	result := reflect.MakeSlice(v.Type(), 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		elem := v.Index(i)
+		if elem.Kind() == reflect.Ptr {
+			if elem.IsNil() {
+				continue
+			}
+			elem = elem.Elem()
+		}
+
+		matched := true
+		for _, m := range matchers {
+			if matched = m.match(elem.FieldByIndex(m.index)); !matched {
+				break
+			}
+		}
+		if matched {
+			result = reflect.Append(result, v.Index(i))
+		}
+	}
+	return result.Interface(), nil
+}
+
+type filterMatcher struct {
+	Filter
+	index []int
+	//filter values converted to the field type
+	values []reflect.Value
+}
+
+func newFilterMatcher(r Resource, fields map[string][]int, filter Filter) (*filterMatcher, error) {
+	index, ok := fields[filter.Name]
+	if ok == false {
+		return nil, fmt.Errorf("%s has no field %s", r.GetType(), filter.Name)
+	}
+
+	m := &filterMatcher{
+		Filter: filter,
+		index:  index,
+	}
+	if filter.Modifier == Null || filter.Modifier == NotNull {
+		return m, nil
+	}
+
+	typ := reflect.TypeOf(r).Elem().FieldByIndex(index).Type
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() == reflect.Slice {
+		if filter.Modifier != Eq && filter.Modifier != Ne {
+			return nil, fmt.Errorf("filter %s on slice field %s isn't supported", filter.Modifier, filter.Name)
+		}
+		typ = typ.Elem()
+	}
+
+	switch filter.Modifier {
+	case Prefix, Suffix, Like, NotLike:
+		if typ.Kind() != reflect.String {
+			return nil, fmt.Errorf("filter %s on non-string field %s", filter.Modifier, filter.Name)
+		}
+	case Lt, Gt, Lte, Gte:
+		if len(filter.Values) != 1 {
+			return nil, fmt.Errorf("filter %s on field %s should have one value", filter.Modifier, filter.Name)
+		}
+	}
+
+	for _, s := range filter.Values {
+		value, err := convertFilterValue(s, typ)
+		if err != nil {
+			return nil, fmt.Errorf("value %s of filter %s is invalid:%s", s, filter.Name, err.Error())
+		}
+		m.values = append(m.values, value)
+	}
+	return m, nil
+}
+
+//all the json fields could be filtered except writeonly fields,
+//otherwise their values could be guessed
+func filterFieldIndexes(typ reflect.Type) map[string][]int {
+	indexes := util.JsonFieldIndexes(typ)
+	if fields := resourceFields(typ); fields != nil {
+		for _, name := range fields.WriteOnlyFields() {
+			delete(indexes, name)
+		}
+	}
+	return indexes
+}
+
+func convertFilterValue(s string, typ reflect.Type) (reflect.Value, error) {
+	v := reflect.New(typ).Elem()
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, typ.Bits())
+		if err != nil {
+			return v, err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		i, err := strconv.ParseUint(s, 10, typ.Bits())
+		if err != nil {
+			return v, err
+		}
+		v.SetUint(i)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, typ.Bits())
+		if err != nil {
+			return v, err
+		}
+		v.SetFloat(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return v, err
+		}
+		v.SetBool(b)
+	case reflect.String:
+		v.SetString(s)
+	default:
+		timeType := reflect.TypeOf(time.Time{})
+		if typ.ConvertibleTo(timeType) {
+			t, err := time.Parse(time.RFC3339, s)
+			if err != nil {
+				return v, err
+			}
+			return reflect.ValueOf(t).Convert(typ), nil
+		}
+		return v, fmt.Errorf("filter on type %v isn't supported", typ)
+	}
+	return v, nil
+}
+
+func (m *filterMatcher) match(field reflect.Value) bool {
+	switch m.Modifier {
+	case Null:
+		return isNullValue(field)
+	case NotNull:
+		return !isNullValue(field)
+	}
+
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return m.Modifier == Ne || m.Modifier == NotLike
+		}
+		field = field.Elem()
+	}
+
+	if field.Kind() == reflect.Slice {
+		contains := false
+		for i := 0; i < field.Len() && !contains; i++ {
+			contains = m.matchAny(field.Index(i), Eq)
+		}
+		return contains == (m.Modifier == Eq)
+	}
+
+	switch m.Modifier {
+	case Ne:
+		return !m.matchAny(field, Eq)
+	case NotLike:
+		return !m.matchAny(field, Like)
+	default:
+		return m.matchAny(field, m.Modifier)
+	}
+}
+
+func (m *filterMatcher) matchAny(field reflect.Value, modifier Modifier) bool {
+	for _, value := range m.values {
+		if matchValue(field, value, modifier) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchValue(field, value reflect.Value, modifier Modifier) bool {
+	switch modifier {
+	case Prefix:
+		return strings.HasPrefix(field.String(), value.String())
+	case Suffix:
+		return strings.HasSuffix(field.String(), value.String())
+	case Like:
+		return strings.Contains(field.String(), value.String())
+	}
+
+	result, err := util.CompareValue(field, value)
+	if err != nil {
+		return false
+	}
+	switch modifier {
+	case Eq:
+		return result == 0
+	case Lt:
+		return result < 0
+	case Gt:
+		return result > 0
+	case Lte:
+		return result <= 0
+	case Gte:
+		return result >= 0
+	default:
+		return false
+	}
+}
+
+func isNullValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	case reflect.Slice, reflect.Map, reflect.String:
+		return v.Len() == 0
+	default:
+		return false
+	}
+}
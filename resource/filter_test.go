@@ -0,0 +1,73 @@
+package resource
+
+import (
+	"net/url"
+	"testing"
+
+	ut "github.com/zdnscloud/cement/unittest"
+)
+
+type filterResource struct {
+	ResourceBase `json:",inline"`
+	Name         string   `json:"name" rest:"required=true"`
+	Count        uint32   `json:"count" rest:"max=10"`
+	Enabled      bool     `json:"enabled" rest:"required=false"`
+	Tags         []string `json:"tags" rest:"required=false"`
+	Comment      *string  `json:"comment" rest:"required=false"`
+	Token        string   `json:"token" rest:"writeonly"`
+	Owner        string   `json:"owner"`
+}
+
+func TestApplyFilters(t *testing.T) {
+	comment := "test"
+	resources := []*filterResource{
+		&filterResource{Name: "n1", Count: 1, Enabled: true, Tags: []string{"a", "b"}, Owner: "u1"},
+		&filterResource{Name: "n2", Count: 2, Tags: []string{"b"}, Comment: &comment},
+		&filterResource{Name: "m3", Count: 3, Enabled: true, Owner: "u1"},
+	}
+	for _, r := range resources {
+		r.SetID(r.Name)
+	}
+
+	cases := []struct {
+		query string
+		names []string
+	}{
+		{"name=n1", []string{"n1"}},
+		{"name=n1&name=m3", []string{"n1", "m3"}},
+		{"name_ne=n1", []string{"n2", "m3"}},
+		{"name_prefix=n", []string{"n1", "n2"}},
+		{"name_suffix=3", []string{"m3"}},
+		{"name_like=2", []string{"n2"}},
+		{"name_notlike=n", []string{"m3"}},
+		{"count_gt=1", []string{"n2", "m3"}},
+		{"count_lte=2&enabled=true", []string{"n1"}},
+		{"tags=b", []string{"n1", "n2"}},
+		{"tags_ne=a", []string{"n2", "m3"}},
+		{"comment_null=", []string{"n1", "m3"}},
+		{"comment_notnull=", []string{"n2"}},
+		{"comment=test", []string{"n2"}},
+		{"tags_null=", []string{"m3"}},
+		{"id=n1", []string{"n1"}},
+		{"owner=u1", []string{"n1", "m3"}},
+	}
+
+	for _, tc := range cases {
+		u, _ := url.Parse("/apis/testing/v1/filterresources?" + tc.query)
+		ctx := &Context{Resource: &filterResource{}, filters: genFilters(u)}
+		result, err := ApplyFilters(ctx, resources)
+		ut.Assert(t, err == nil, "query %s get err %v", tc.query, err)
+		var names []string
+		for _, r := range result.([]*filterResource) {
+			names = append(names, r.Name)
+		}
+		ut.Equal(t, names, tc.names)
+	}
+
+	for _, query := range []string{"unknown=1", "count=a", "enabled=yes", "count_prefix=1", "tags_gt=a", "count_lt=1&count_lt=2", "token=t1"} {
+		u, _ := url.Parse("/apis/testing/v1/filterresources?" + query)
+		ctx := &Context{Resource: &filterResource{}, filters: genFilters(u)}
+		_, err := ApplyFilters(ctx, resources)
+		ut.Assert(t, err != nil, "query %s should be invalid", query)
+	}
+}
@@ -15,6 +15,10 @@ const (
 	ListMethod   string = "List"
 	GetMethod    string = "Get"
 	ActionMethod string = "Action"
+	PatchMethod  string = "Patch"
+	WatchMethod  string = "Watch"
+
+	UpdateStatusMethod string = "UpdateStatus"
 )
 
 type CreateHandler func(*Context) (Resource, *goresterr.APIError)
@@ -23,6 +27,9 @@ type UpdateHandler func(*Context) (Resource, *goresterr.APIError)
 type ListHandler func(*Context) (interface{}, *goresterr.APIError)
 type GetHandler func(*Context) (Resource, *goresterr.APIError)
 type ActionHandler func(*Context) (interface{}, *goresterr.APIError)
+type PatchHandler func(*Context) (Resource, *goresterr.APIError)
+type WatchHandler func(*Context) (<-chan WatchEvent, *goresterr.APIError)
+type UpdateStatusHandler func(*Context) (Resource, *goresterr.APIError)
 
 type Handler interface {
 	GetCreateHandler() CreateHandler
@@ -31,6 +38,9 @@ type Handler interface {
 	GetListHandler() ListHandler
 	GetGetHandler() GetHandler
 	GetActionHandler() ActionHandler
+	GetPatchHandler() PatchHandler
+	GetWatchHandler() WatchHandler
+	GetUpdateStatusHandler() UpdateStatusHandler
 }
 
 func HandlerAdaptor(obj interface{}) (Handler, error) {
@@ -92,6 +102,33 @@ func HandlerAdaptor(obj interface{}) (Handler, error) {
 		}
 	}
 
+	if mv := val.MethodByName(PatchMethod); mv.IsValid() {
+		if method, ok := mv.Interface().(func(*Context) (Resource, *goresterr.APIError)); ok {
+			handler.patchHandler = method
+			hasAnyHandler = true
+		} else {
+			return nil, fmt.Errorf("handler has '%s' method but with wrong signature", PatchMethod)
+		}
+	}
+
+	if mv := val.MethodByName(WatchMethod); mv.IsValid() {
+		if method, ok := mv.Interface().(func(*Context) (<-chan WatchEvent, *goresterr.APIError)); ok {
+			handler.watchHandler = method
+			hasAnyHandler = true
+		} else {
+			return nil, fmt.Errorf("handler has '%s' method but with wrong signature", WatchMethod)
+		}
+	}
+
+	if mv := val.MethodByName(UpdateStatusMethod); mv.IsValid() {
+		if method, ok := mv.Interface().(func(*Context) (Resource, *goresterr.APIError)); ok {
+			handler.updateStatusHandler = method
+			hasAnyHandler = true
+		} else {
+			return nil, fmt.Errorf("handler has '%s' method but with wrong signature", UpdateStatusMethod)
+		}
+	}
+
 	if hasAnyHandler == false {
 		return nil, fmt.Errorf("handler doesn't have any handle method")
 	} else {
@@ -102,12 +139,15 @@ func HandlerAdaptor(obj interface{}) (Handler, error) {
 var _ Handler = &DefaultHandler{}
 
 type DefaultHandler struct {
-	createHandler CreateHandler
-	deleteHandler DeleteHandler
-	updateHandler UpdateHandler
-	listHandler   ListHandler
-	getHandler    GetHandler
-	actionHandler ActionHandler
+	createHandler       CreateHandler
+	deleteHandler       DeleteHandler
+	updateHandler       UpdateHandler
+	listHandler         ListHandler
+	getHandler          GetHandler
+	actionHandler       ActionHandler
+	patchHandler        PatchHandler
+	watchHandler        WatchHandler
+	updateStatusHandler UpdateStatusHandler
 }
 
 func (h *DefaultHandler) GetCreateHandler() CreateHandler {
@@ -134,9 +174,21 @@ func (h *DefaultHandler) GetActionHandler() ActionHandler {
 	return h.actionHandler
 }
 
+func (h *DefaultHandler) GetPatchHandler() PatchHandler {
+	return h.patchHandler
+}
+
+func (h *DefaultHandler) GetWatchHandler() WatchHandler {
+	return h.watchHandler
+}
+
+func (h *DefaultHandler) GetUpdateStatusHandler() UpdateStatusHandler {
+	return h.updateStatusHandler
+}
+
 func GetCollectionMethods(handler Handler) []HttpMethod {
 	var collectionMethods []HttpMethod
-	if handler.GetListHandler() != nil {
+	if handler.GetListHandler() != nil || handler.GetWatchHandler() != nil {
 		collectionMethods = append(collectionMethods, http.MethodGet)
 	}
 	if handler.GetCreateHandler() != nil {
@@ -145,6 +197,40 @@ func GetCollectionMethods(handler Handler) []HttpMethod {
 	return collectionMethods
 }
 
+//POST is supported by collection if the kind has collection actions,
+//singleton has no collection
+func GetKindCollectionMethods(kind ResourceKind, handler Handler) []HttpMethod {
+	if kind.IsSingleton() {
+		return nil
+	}
+
+	collectionMethods := GetCollectionMethods(handler)
+	if handler.GetCreateHandler() == nil && handler.GetActionHandler() != nil && len(kind.GetCollectionActions()) > 0 {
+		collectionMethods = append(collectionMethods, http.MethodPost)
+	}
+	return collectionMethods
+}
+
+//singleton only supports GET, PUT and PATCH
+func GetKindResourceMethods(kind ResourceKind, handler Handler) []HttpMethod {
+	resourceMethods := GetResourceMethods(handler)
+	if kind.IsSingleton() == false {
+		return resourceMethods
+	}
+
+	var singletonMethods []HttpMethod
+	for _, method := range resourceMethods {
+		if IsSingletonMethod(method) {
+			singletonMethods = append(singletonMethods, method)
+		}
+	}
+	return singletonMethods
+}
+
+func IsSingletonMethod(method HttpMethod) bool {
+	return method == http.MethodGet || method == http.MethodPut || method == http.MethodPatch
+}
+
 func GetResourceMethods(handler Handler) []HttpMethod {
 	var resourceMethods []HttpMethod
 	if handler.GetGetHandler() != nil {
@@ -156,6 +242,9 @@ func GetResourceMethods(handler Handler) []HttpMethod {
 	if handler.GetUpdateHandler() != nil {
 		resourceMethods = append(resourceMethods, http.MethodPut)
 	}
+	if handler.GetPatchHandler() != nil {
+		resourceMethods = append(resourceMethods, http.MethodPatch)
+	}
 	if handler.GetActionHandler() != nil {
 		resourceMethods = append(resourceMethods, http.MethodPost)
 	}
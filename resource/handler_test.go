@@ -14,6 +14,12 @@ func (h *dumbHandlerTwo) Create(ctx *Context) (Resource, *err.APIError) {
 	return &dumbResource{Number: 60}, nil
 }
 
+type dumbPatchHandler struct{}
+
+func (h *dumbPatchHandler) Patch(ctx *Context) (Resource, *err.APIError) {
+	return &dumbResource{Number: 70}, nil
+}
+
 type emptyHandler struct{}
 
 func TestHandlerGen(t *testing.T) {
@@ -50,6 +56,12 @@ func TestHandlerGen(t *testing.T) {
 	ut.Equal(t, len(resourceMethods), 0)
 	ut.Equal(t, collectionMethods, []HttpMethod{http.MethodPost})
 
+	handler, _ = HandlerAdaptor(&dumbPatchHandler{})
+	ut.Equal(t, GetResourceMethods(handler), []HttpMethod{http.MethodPatch})
+	patchResult, err := handler.GetPatchHandler()(nil)
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, patchResult.(*dumbResource).Number, 70)
+
 	_, err_ := HandlerAdaptor(&emptyHandler{})
 	ut.Assert(t, err_ != nil, "")
 }
@@ -0,0 +1,136 @@
+package resource
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+const (
+	LimitQueryKey    = "limit"
+	ContinueQueryKey = "continue"
+)
+
+type Pagination struct {
+	Limit int `json:"limit"`
+	Total int `json:"total"`
+	//token to get the next page, empty means it's the last page
+	Continue string `json:"continue,omitempty"`
+
+	offset int
+	paged  bool
+	query  url.Values
+}
+
+//return nil if limit isn't specified in url
+func genPagination(url *url.URL) (*Pagination, error) {
+	query := url.Query()
+	limitStr := query.Get(LimitQueryKey)
+	token := query.Get(ContinueQueryKey)
+	if limitStr == "" {
+		if token != "" {
+			return nil, fmt.Errorf("%s should be used with %s", ContinueQueryKey, LimitQueryKey)
+		}
+		return nil, nil
+	}
+
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit <= 0 {
+		return nil, fmt.Errorf("%s should be a positive integer", LimitQueryKey)
+	}
+
+	offset := 0
+	if token != "" {
+		if offset, err = decodeContinueToken(token); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Pagination{
+		Limit:  limit,
+		offset: offset,
+		query:  query,
+	}, nil
+}
+
+func encodeContinueToken(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(offset)))
+}
+
+func decodeContinueToken(token string) (int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err == nil {
+		if offset, err := strconv.Atoi(string(data)); err == nil && offset >= 0 {
+			return offset, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid %s token %s", ContinueQueryKey, token)
+}
+
+//index of the first resource in current page
+func (p *Pagination) GetOffset() int {
+	return p.offset
+}
+
+//list handler which pages resources by itself should
+//return only the resources in current page and call this
+//function with the count of all the resources
+func (p *Pagination) SetTotal(total int) {
+	p.Total = total
+	p.paged = true
+}
+
+func (p *Pagination) apply(resources []Resource) []Resource {
+	if p.paged == false {
+		p.Total = len(resources)
+		if p.offset >= len(resources) {
+			resources = resources[:0]
+		} else {
+			end := p.offset + p.Limit
+			if end > len(resources) {
+				end = len(resources)
+			}
+			resources = resources[p.offset:end]
+		}
+	}
+
+	if p.offset+p.Limit < p.Total {
+		p.Continue = encodeContinueToken(p.offset + p.Limit)
+	}
+	return resources
+}
+
+//query of the next page, return nil if current page is the last one
+func (p *Pagination) NextPageQuery() url.Values {
+	if p.Continue == "" {
+		return nil
+	}
+	return p.pageQuery(p.Continue)
+}
+
+//query of the previous page, return nil if current page is the first one
+func (p *Pagination) PrevPageQuery() url.Values {
+	if p.offset == 0 {
+		return nil
+	}
+
+	offset := p.offset - p.Limit
+	if offset <= 0 {
+		return p.pageQuery("")
+	}
+	return p.pageQuery(encodeContinueToken(offset))
+}
+
+func (p *Pagination) pageQuery(token string) url.Values {
+	query := make(url.Values)
+	for k, v := range p.query {
+		query[k] = v
+	}
+	if token == "" {
+		query.Del(ContinueQueryKey)
+	} else {
+		query.Set(ContinueQueryKey, token)
+	}
+	return query
+}
@@ -0,0 +1,160 @@
+package resource
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+
+	goresterr "github.com/zdnscloud/gorest/error"
+	"github.com/zdnscloud/gorest/util"
+)
+
+type PatchType string
+
+const (
+	JSONPatchType  PatchType = "application/json-patch+json"
+	MergePatchType PatchType = "application/merge-patch+json"
+)
+
+type Patch struct {
+	Type PatchType
+	Data []byte
+
+	//json name of the top level fields modified by the patch
+	fields     []string
+	operations []util.JSONPatchOperation
+}
+
+//content type application/json is treated as merge patch
+func NewPatch(contentType string, data []byte) (*Patch, *goresterr.APIError) {
+	typ := MergePatchType
+	if contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil {
+			return nil, goresterr.NewAPIError(goresterr.UnsupportedMediaType,
+				fmt.Sprintf("invalid content type %s", contentType))
+		}
+		switch mediaType {
+		case string(JSONPatchType):
+			typ = JSONPatchType
+		case string(MergePatchType), "application/json":
+		default:
+			return nil, goresterr.NewAPIError(goresterr.UnsupportedMediaType,
+				fmt.Sprintf("content type %s isn't supported by patch", mediaType))
+		}
+	}
+
+	patch := &Patch{
+		Type: typ,
+		Data: data,
+	}
+	if err := patch.parse(); err != nil {
+		return nil, goresterr.NewAPIError(goresterr.InvalidBodyContent, err.Error())
+	}
+	return patch, nil
+}
+
+func (p *Patch) parse() error {
+	if p.Type == MergePatchType {
+		objMap := make(map[string]interface{})
+		if err := json.Unmarshal(p.Data, &objMap); err != nil {
+			return fmt.Errorf("merge patch isn't a string map:%s", err.Error())
+		}
+		for name := range objMap {
+			p.fields = append(p.fields, name)
+		}
+		return nil
+	}
+
+	ops, err := util.ParseJSONPatch(p.Data)
+	if err != nil {
+		return err
+	}
+	for _, op := range ops {
+		if err := p.addModifiedField(op.Path); err != nil {
+			return err
+		}
+		if op.Op == util.PatchOpMove {
+			if err := p.addModifiedField(op.From); err != nil {
+				return err
+			}
+		}
+	}
+	p.operations = ops
+	return nil
+}
+
+func (p *Patch) addModifiedField(pointer string) error {
+	tokens, _ := util.ParseJSONPointer(pointer)
+	if len(tokens) == 0 {
+		return fmt.Errorf("patch the whole resource isn't supported")
+	}
+	for _, name := range p.fields {
+		if name == tokens[0] {
+			return nil
+		}
+	}
+	p.fields = append(p.fields, tokens[0])
+	return nil
+}
+
+//remove the modification of the specified fields, for json patch
+//operations which modify these fields are removed
+func (p *Patch) RemoveFields(names []string) error {
+	if p.Type == MergePatchType {
+		objMap := make(map[string]interface{})
+		if err := json.Unmarshal(p.Data, &objMap); err != nil {
+			return err
+		}
+		for _, name := range names {
+			delete(objMap, name)
+		}
+		data, err := json.Marshal(objMap)
+		if err != nil {
+			return err
+		}
+		p.Data = data
+	} else {
+		var ops []util.JSONPatchOperation
+		for _, op := range p.operations {
+			if isPatchedField(names, op.Path) == false && (op.Op != util.PatchOpMove || isPatchedField(names, op.From) == false) {
+				ops = append(ops, op)
+			}
+		}
+		if ops == nil {
+			ops = []util.JSONPatchOperation{}
+		}
+		data, err := json.Marshal(ops)
+		if err != nil {
+			return err
+		}
+		p.Data = data
+	}
+
+	p.fields = nil
+	p.operations = nil
+	return p.parse()
+}
+
+func isPatchedField(names []string, pointer string) bool {
+	tokens, _ := util.ParseJSONPointer(pointer)
+	for _, name := range names {
+		if len(tokens) > 0 && tokens[0] == name {
+			return true
+		}
+	}
+	return false
+}
+
+//apply the patch to the json document of a resource
+func (p *Patch) Apply(doc []byte) ([]byte, error) {
+	if p.Type == MergePatchType {
+		return util.ApplyMergePatch(doc, p.Data)
+	} else {
+		return util.ApplyJSONPatch(doc, p.operations)
+	}
+}
+
+func (p *Patch) GetFields() []string {
+	return p.fields
+}
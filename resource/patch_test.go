@@ -0,0 +1,23 @@
+package resource
+
+import (
+	"testing"
+
+	ut "github.com/zdnscloud/cement/unittest"
+)
+
+func TestPatchRemoveFields(t *testing.T) {
+	patch, err := NewPatch("", []byte(`{"name":"ben","counter":1}`))
+	ut.Assert(t, err == nil, "")
+	ut.Assert(t, patch.RemoveFields([]string{"counter"}) == nil, "")
+	ut.Equal(t, patch.GetFields(), []string{"name"})
+	ut.Equal(t, string(patch.Data), `{"name":"ben"}`)
+
+	patch, err = NewPatch(string(JSONPatchType), []byte(`[{"op":"replace","path":"/counter","value":1},{"op":"move","from":"/counter","path":"/age"},{"op":"add","path":"/name","value":"ben"}]`))
+	ut.Assert(t, err == nil, "")
+	ut.Assert(t, patch.RemoveFields([]string{"counter"}) == nil, "")
+	ut.Equal(t, patch.GetFields(), []string{"name"})
+	result, err_ := patch.Apply([]byte(`{"counter":2}`))
+	ut.Assert(t, err_ == nil, "")
+	ut.Equal(t, string(result), `{"counter":2,"name":"ben"}`)
+}
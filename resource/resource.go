@@ -15,7 +15,11 @@ const (
 	SelfLink       ResourceLinkType = "self"
 	UpdateLink     ResourceLinkType = "update"
 	RemoveLink     ResourceLinkType = "remove"
+	PatchLink      ResourceLinkType = "patch"
+	StatusLink     ResourceLinkType = "status"
 	CollectionLink ResourceLinkType = "collection"
+	NextLink       ResourceLinkType = "next"
+	PrevLink       ResourceLinkType = "prev"
 )
 
 type Resource interface {
@@ -43,6 +47,13 @@ type Resource interface {
 
 	GetAction() *Action
 	SetAction(*Action)
+
+	GetPatch() *Patch
+	SetPatch(*Patch)
+
+	//resource is updated through status subresource
+	IsStatusUpdate() bool
+	SetStatusUpdate(bool)
 }
 
 //struct implement ResourceKind
@@ -54,30 +65,102 @@ type ResourceKind interface {
 	//NOTE: default field shouldn't include map
 	//json unmarshal will merge map, in this case
 	//when real data is provided, it will merge with
-	//default value
+	//default value, use default tag for map instead,
+	//eg: rest:"default=a:1|b:2"
 	CreateDefaultResource() Resource
 	GetActions() []Action
+	//actions applied to the collection, they share the action handler
+	//with resource actions, handler could tell them apart by resource id,
+	//resource actions posted to the collection are accepted as well
+	GetCollectionActions() []Action
 	SupportAsyncDelete() bool
+	//whether the resource collection could be embedded into
+	//parent resource by expand query
+	SupportExpand() bool
+	//singleton has no id and at most one per parent, it's served
+	//at the path of its kind name, eg: clusters/c1/dnsconfig
+	IsSingleton() bool
+	//fields with status tag are only updated through status
+	//subresource, eg: clusters/c1/status, and ignored by update and
+	//patch, update handler gets the status returned by get handler,
+	//without get handler, it should keep the status by itself
+	SupportStatus() bool
+}
+
+//kind could implement it to override the guessed names,
+//empty string means using the guessed one
+type ResourceNamer interface {
+	//lowercase singluar, used as resource type
+	GetKindName() string
+	//plural name, used in url
+	GetResourceName() string
+}
+
+//kind could implement it to check the resource as a whole after
+//the fields are validated, it's called before create, update and
+//patch handler, error other than api error is treated as invalid body
+type ResourceValidator interface {
+	Validate(ctx *Context) error
+}
+
+//methods with pointer receiver are also checked
+func HasValidator(kind ResourceKind) bool {
+	if _, ok := kind.(ResourceValidator); ok {
+		return true
+	}
+	_, ok := reflect.New(structType(kind)).Interface().(ResourceValidator)
+	return ok
 }
 
 //lowercase singluar
 //eg: type Node struct -> node
 func DefaultKindName(t interface{}) string {
+	typ := structType(t)
+	if namer, ok := getResourceNamer(t, typ); ok {
+		if name := namer.GetKindName(); name != "" {
+			return name
+		}
+	}
+	return strings.ToLower(typ.Name())
+}
+
+//resource name is lowercase, plural word, singleton has only one
+//resource so its kind name is used
+//eg: type Node struct -> nodes
+func DefaultResourceName(t interface{}) string {
+	if namer, ok := getResourceNamer(t, structType(t)); ok {
+		if name := namer.GetResourceName(); name != "" {
+			return name
+		}
+	}
+	if kind, ok := t.(ResourceKind); ok && kind.IsSingleton() {
+		return DefaultKindName(t)
+	}
+	return util.GuessPluralName(DefaultKindName(t))
+}
+
+func structType(t interface{}) reflect.Type {
 	typ := reflect.TypeOf(t)
-	kind := typ.Kind()
-	if kind == reflect.Ptr {
+	if typ.Kind() == reflect.Ptr {
 		typ = typ.Elem()
-		kind = typ.Kind()
 	}
-	if kind != reflect.Struct {
+	if typ.Kind() != reflect.Struct {
 		panic("invalid param, it's not a struct or a struct pointer")
 	}
+	return typ
+}
 
-	return strings.ToLower(typ.Name())
+//names are static, so methods with pointer receiver are
+//also checked even if t isn't a pointer
+func getResourceNamer(t interface{}, typ reflect.Type) (ResourceNamer, bool) {
+	if namer, ok := t.(ResourceNamer); ok {
+		return namer, true
+	}
+	namer, ok := reflect.New(typ).Interface().(ResourceNamer)
+	return namer, ok
 }
 
-//resource name is lowercase, plural word
-//eg: type Node struct -> nodes
-func DefaultResourceName(t interface{}) string {
-	return util.GuessPluralName(DefaultKindName(t))
+func IsSingleton(r Resource) bool {
+	kind, ok := r.(ResourceKind)
+	return ok && kind.IsSingleton()
 }
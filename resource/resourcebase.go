@@ -12,9 +12,11 @@ type ResourceBase struct {
 	CreationTimestamp ISOTime                           `json:"creationTimestamp,omitempty"`
 	DeletionTimestamp ISOTime                           `json:"deletionTimestamp,omitempty"`
 
-	action *Action  `json:"-"`
-	parent Resource `json:"-"`
-	schema Schema   `json:"-"`
+	action       *Action  `json:"-"`
+	patch        *Patch   `json:"-"`
+	statusUpdate bool     `json:"-"`
+	parent       Resource `json:"-"`
+	schema       Schema   `json:"-"`
 }
 
 func (r ResourceBase) GetParents() []ResourceKind {
@@ -29,10 +31,26 @@ func (r ResourceBase) GetActions() []Action {
 	return nil
 }
 
+func (r ResourceBase) GetCollectionActions() []Action {
+	return nil
+}
+
 func (r ResourceBase) SupportAsyncDelete() bool {
 	return false
 }
 
+func (r ResourceBase) SupportExpand() bool {
+	return true
+}
+
+func (r ResourceBase) IsSingleton() bool {
+	return false
+}
+
+func (r ResourceBase) SupportStatus() bool {
+	return false
+}
+
 var _ ResourceKind = ResourceBase{}
 
 func (r *ResourceBase) GetID() string {
@@ -91,6 +109,22 @@ func (r *ResourceBase) SetAction(action *Action) {
 	r.action = action
 }
 
+func (r *ResourceBase) GetPatch() *Patch {
+	return r.patch
+}
+
+func (r *ResourceBase) SetPatch(patch *Patch) {
+	r.patch = patch
+}
+
+func (r *ResourceBase) IsStatusUpdate() bool {
+	return r.statusUpdate
+}
+
+func (r *ResourceBase) SetStatusUpdate(statusUpdate bool) {
+	r.statusUpdate = statusUpdate
+}
+
 func (r *ResourceBase) SetType(typ string) {
 	r.Type = typ
 }
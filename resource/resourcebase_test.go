@@ -20,3 +20,49 @@ func TestKindAndResourceName(t *testing.T) {
 	ut.Equal(t, DefaultResourceName(Deployment{}), "deployments")
 	ut.Equal(t, DefaultResourceName(&Deployment{}), "deployments")
 }
+
+type Sysconfig struct {
+	ResourceBase
+}
+
+func (s Sysconfig) IsSingleton() bool {
+	return true
+}
+
+func TestSingletonResourceName(t *testing.T) {
+	ut.Equal(t, DefaultResourceName(Sysconfig{}), "sysconfig")
+	ut.Equal(t, DefaultResourceName(&Sysconfig{}), "sysconfig")
+}
+
+type Person struct {
+	ResourceBase
+}
+
+func (p *Person) GetKindName() string {
+	return ""
+}
+
+func (p *Person) GetResourceName() string {
+	return "people"
+}
+
+type DNSData struct {
+	ResourceBase
+}
+
+func (d DNSData) GetKindName() string {
+	return "dnsdata"
+}
+
+func (d DNSData) GetResourceName() string {
+	return "dnsdata"
+}
+
+func TestCustomKindAndResourceName(t *testing.T) {
+	ut.Equal(t, DefaultKindName(Person{}), "person")
+	ut.Equal(t, DefaultResourceName(Person{}), "people")
+	ut.Equal(t, DefaultResourceName(&Person{}), "people")
+	ut.Equal(t, DefaultKindName(DNSData{}), "dnsdata")
+	ut.Equal(t, DefaultKindName(&DNSData{}), "dnsdata")
+	ut.Equal(t, DefaultResourceName(DNSData{}), "dnsdata")
+}
@@ -0,0 +1,33 @@
+package resource
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/zdnscloud/gorest/resource/schema/resourcefield"
+)
+
+//field metadata of resource kinds keyed by struct type, nil value means
+//the kind has no field with rest tag
+var resourceFieldCache sync.Map
+
+//field metadata built from the rest tags of the resource type, tags of
+//resource kind are checked when schema is imported
+func resourceFields(typ reflect.Type) resourcefield.ResourceField {
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if fields, ok := resourceFieldCache.Load(typ); ok {
+		f, _ := fields.(resourcefield.ResourceField)
+		return f
+	}
+
+	var fields resourcefield.ResourceField
+	if typ.Kind() == reflect.Struct {
+		if f, err := resourcefield.New(typ); err == nil && f != nil {
+			fields = f
+		}
+	}
+	resourceFieldCache.Store(typ, fields)
+	return fields
+}
@@ -14,6 +14,8 @@ type SchemaManager interface {
 	//for GET/ DELETE, return empty resource, with id and parent set,
 	//for POST and PUT, the resource unmarshal from body will be returned
 	//also support default value and validation check
+	//for PATCH, the patch is attached to the resource, merge patch is
+	//also applied to an empty resource to validate the modified fields
 	CreateResourceFromRequest(*http.Request) (Resource, *goresterr.APIError)
 
 	//based on handler to generate route for the resources
@@ -25,5 +27,22 @@ type Schema interface {
 	GetHandler() Handler
 	AddLinksToResource(r Resource, httpSchemeAndHost string) error
 	AddLinksToResourceCollection(rs *ResourceCollection, httpSchemeAndHost string) error
+	//create an empty resource of the child schema with resource name
+	CreateChildResource(parent Resource, name string) (Resource, error)
+	//apply the patch of r to current resource and validate the modified fields,
+	//current is nil means apply the patch to an empty resource
+	ApplyPatch(r Resource, current Resource) (Resource, *goresterr.APIError)
+	//json names of the fields which can't be changed by update
+	GetImmutableFields() []string
+	//return error if any immutable field of r is different with current
+	CheckImmutableFields(r Resource, current Resource) *goresterr.APIError
+	//json names of the fields only updated through status subresource,
+	//nil if the kind doesn't support status
+	GetStatusFields() []string
+	//copy status fields of current to r, since update of the resource
+	//doesn't accept status fields
+	KeepStatusFields(r Resource, current Resource) *goresterr.APIError
+	//return nil if the resource kind has no async action
+	GetTaskManager() *TaskManager
 	WriteJsonDoc(path string) error
 }
@@ -3,10 +3,14 @@ package schema
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"sync"
 	"testing"
 
 	ut "github.com/zdnscloud/cement/unittest"
+	goresterr "github.com/zdnscloud/gorest/error"
+	"github.com/zdnscloud/gorest/resource"
 )
 
 type podGenJson struct {
@@ -228,8 +232,292 @@ func TestAction(t *testing.T) {
 	ut.Equal(t, action.Name, "move")
 	ut.Equal(t, action.Input.(*Location).NodeName, "n1")
 
+	for body, code := range map[string]goresterr.ErrorCode{
+		`{}`:               goresterr.MissingRequired,
+		`{"nodeName":"n"}`: goresterr.MinLengthExceeded,
+	} {
+		req, _ = http.NewRequest(http.MethodPost, url, bytes.NewBufferString(body))
+		_, err = mgr.CreateResourceFromRequest(req)
+		ut.Assert(t, err != nil, "action input %s should be invalid", body)
+		ut.Equal(t, err.ErrorCode, code)
+	}
+
 	url = "/apis/testing/v1/clusters/c1/namespaces/n1/deployments/d1/pods/p1?action=me"
 	req, _ = http.NewRequest(http.MethodPost, url, nil)
 	_, err = mgr.CreateResourceFromRequest(req)
 	ut.Assert(t, err != nil, "")
+
+	url = "/apis/testing/v1/clusters/c1/namespaces/n1/deployments/d1/pods?action=evict"
+	req, _ = http.NewRequest(http.MethodPost, url, bytes.NewBufferString(`{"nodeName":"n2"}`))
+	r, err = mgr.CreateResourceFromRequest(req)
+	ut.Assert(t, err == nil, "get err:%v", err)
+	ut.Equal(t, r.GetID(), "")
+	ut.Equal(t, r.GetAction().Name, "evict")
+	ut.Equal(t, r.GetAction().Input.(*EvictCondition).NodeName, "n2")
+
+	//resource action posted to collection is still accepted
+	url = "/apis/testing/v1/clusters/c1/namespaces/n1/deployments/d1/pods?action=move"
+	req, _ = http.NewRequest(http.MethodPost, url, bytes.NewBufferString(string(reqBody)))
+	r, err = mgr.CreateResourceFromRequest(req)
+	ut.Assert(t, err == nil, "get err:%v", err)
+	ut.Equal(t, r.GetID(), "")
+	ut.Equal(t, r.GetAction().Name, "move")
+
+	//collection action can't be applied to resource
+	url = "/apis/testing/v1/clusters/c1/namespaces/n1/deployments/d1/pods/p1?action=evict"
+	req, _ = http.NewRequest(http.MethodPost, url, bytes.NewBufferString(`{"nodeName":"n2"}`))
+	_, err = mgr.CreateResourceFromRequest(req)
+	ut.Assert(t, err != nil, "")
+}
+
+type Router struct {
+	resource.ResourceBase `json:",inline"`
+}
+
+type RouteInput struct {
+	Target string `json:"target" rest:"required=true"`
+	Metric int    `json:"metric" rest:"default=10"`
+}
+
+//actions are cached, so all the requests get the same input prototype
+var routerActions = []resource.Action{
+	resource.Action{
+		Name:  "route",
+		Input: &RouteInput{},
+	},
+}
+
+func (r Router) GetActions() []resource.Action {
+	return routerActions
+}
+
+func TestConcurrentAction(t *testing.T) {
+	mgr := NewSchemaManager()
+	mgr.MustImport(&version, Router{}, &resource.DumbHandler{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			target := fmt.Sprintf("t%d", i)
+			req, _ := http.NewRequest(http.MethodPost, "/apis/testing/v1/routers/r1?action=route",
+				bytes.NewBufferString(fmt.Sprintf(`{"target":"%s"}`, target)))
+			r, err := mgr.CreateResourceFromRequest(req)
+			ut.Assert(t, err == nil, "get err:%v", err)
+			input := r.GetAction().Input.(*RouteInput)
+			ut.Assert(t, input != routerActions[0].Input, "action input shouldn't be shared")
+			ut.Equal(t, input.Target, target)
+			ut.Equal(t, input.Metric, 10)
+		}(i)
+	}
+	wg.Wait()
+	ut.Equal(t, *(routerActions[0].Input.(*RouteInput)), RouteInput{})
+}
+
+type Zone struct {
+	resource.ResourceBase `json:",inline"`
+	Name                  string `json:"name" rest:"required=true,minLen=2,maxLen=10"`
+	Ttl                   int    `json:"ttl" rest:"required=true,min=60,max=3600"`
+	Comment               string `json:"comment"`
+}
+
+type zoneHandler struct{}
+
+func (h *zoneHandler) Patch(ctx *resource.Context) (resource.Resource, *goresterr.APIError) {
+	return ctx.Resource, nil
+}
+
+func TestPatch(t *testing.T) {
+	mgr := NewSchemaManager()
+	mgr.MustImport(&version, Zone{}, &zoneHandler{})
+	url := "/apis/testing/v1/zones/z1"
+
+	req, _ := http.NewRequest(http.MethodPatch, url, bytes.NewBufferString(`{"ttl":120}`))
+	req.Header.Set("Content-Type", string(resource.MergePatchType))
+	r, err := mgr.CreateResourceFromRequest(req)
+	ut.Assert(t, err == nil, "get err:%v", err)
+	ut.Equal(t, r.GetID(), "z1")
+	ut.Equal(t, r.(*Zone).Ttl, 120)
+	ut.Equal(t, r.GetPatch().GetFields(), []string{"ttl"})
+
+	current := &Zone{Name: "zdns", Ttl: 60, Comment: "old"}
+	patched, err := r.GetSchema().ApplyPatch(r, current)
+	ut.Assert(t, err == nil, "get err:%v", err)
+	ut.Equal(t, patched.GetID(), "z1")
+	ut.Equal(t, patched.(*Zone).Name, "zdns")
+	ut.Equal(t, patched.(*Zone).Ttl, 120)
+	ut.Equal(t, patched.(*Zone).Comment, "old")
+
+	req, _ = http.NewRequest(http.MethodPatch, url, bytes.NewBufferString(`{"ttl":10}`))
+	_, err = mgr.CreateResourceFromRequest(req)
+	ut.Assert(t, err != nil && err.ErrorCode == goresterr.MinLimitExceeded, "")
+
+	req, _ = http.NewRequest(http.MethodPatch, url, bytes.NewBufferString(`{"ttl":120}`))
+	req.Header.Set("Content-Type", "text/plain")
+	_, err = mgr.CreateResourceFromRequest(req)
+	ut.Assert(t, err != nil && err.ErrorCode == goresterr.UnsupportedMediaType, "")
+
+	req, _ = http.NewRequest(http.MethodPatch, url, bytes.NewBufferString(
+		`[{"op":"replace","path":"/name","value":"zcloud"},{"op":"remove","path":"/comment"}]`))
+	req.Header.Set("Content-Type", string(resource.JSONPatchType))
+	r, err = mgr.CreateResourceFromRequest(req)
+	ut.Assert(t, err == nil, "get err:%v", err)
+	ut.Equal(t, r.GetPatch().GetFields(), []string{"name", "comment"})
+	patched, err = r.GetSchema().ApplyPatch(r, current)
+	ut.Assert(t, err == nil, "get err:%v", err)
+	ut.Equal(t, patched.(*Zone).Name, "zcloud")
+	ut.Equal(t, patched.(*Zone).Ttl, 60)
+	ut.Equal(t, patched.(*Zone).Comment, "")
+
+	//remove required field
+	req, _ = http.NewRequest(http.MethodPatch, url, bytes.NewBufferString(`[{"op":"remove","path":"/name"}]`))
+	req.Header.Set("Content-Type", string(resource.JSONPatchType))
+	r, err = mgr.CreateResourceFromRequest(req)
+	ut.Assert(t, err == nil, "get err:%v", err)
+	_, err = r.GetSchema().ApplyPatch(r, current)
+	ut.Assert(t, err != nil && err.ErrorCode == goresterr.MissingRequired, "")
+
+	//untouched invalid field isn't validated
+	current.Ttl = 1
+	req, _ = http.NewRequest(http.MethodPatch, url, bytes.NewBufferString(`{"comment":"new"}`))
+	r, _ = mgr.CreateResourceFromRequest(req)
+	_, err = r.GetSchema().ApplyPatch(r, current)
+	ut.Assert(t, err == nil, "get err:%v", err)
+}
+
+type Record struct {
+	resource.ResourceBase `json:",inline"`
+	Name                  string            `json:"name" rest:"required=true"`
+	Ttl                   int               `json:"ttl" rest:"default=3600,min=60,max=86400"`
+	Rdatas                []string          `json:"rdatas" rest:"default=1.1.1.1|2.2.2.2"`
+	Labels                map[string]string `json:"labels" rest:"default=zone:zdns|view:default"`
+}
+
+func TestDefaultTag(t *testing.T) {
+	mgr := NewSchemaManager()
+	mgr.MustImport(&version, Record{}, &resource.DumbHandler{})
+	url := "/apis/testing/v1/records"
+
+	req, _ := http.NewRequest(http.MethodPost, url, bytes.NewBufferString(`{"name":"www"}`))
+	r, err := mgr.CreateResourceFromRequest(req)
+	ut.Assert(t, err == nil, "get err:%v", err)
+	ut.Equal(t, r.(*Record).Ttl, 3600)
+	ut.Equal(t, r.(*Record).Rdatas, []string{"1.1.1.1", "2.2.2.2"})
+	ut.Equal(t, r.(*Record).Labels, map[string]string{"zone": "zdns", "view": "default"})
+
+	//map in request isn't merged with default value
+	req, _ = http.NewRequest(http.MethodPut, url+"/www", bytes.NewBufferString(`{"name":"www","ttl":60,"labels":{"owner":"ben"}}`))
+	r, err = mgr.CreateResourceFromRequest(req)
+	ut.Assert(t, err == nil, "get err:%v", err)
+	ut.Equal(t, r.(*Record).Ttl, 60)
+	ut.Equal(t, r.(*Record).Labels, map[string]string{"owner": "ben"})
+
+	//fields not in patch keep unchanged
+	req, _ = http.NewRequest(http.MethodPatch, url+"/www", bytes.NewBufferString(`{"name":"ftp"}`))
+	r, err = mgr.CreateResourceFromRequest(req)
+	ut.Assert(t, err == nil, "get err:%v", err)
+	patched, err := r.GetSchema().ApplyPatch(r, &Record{Name: "www", Ttl: 60})
+	ut.Assert(t, err == nil, "get err:%v", err)
+	ut.Equal(t, patched.(*Record).Ttl, 60)
+	ut.Equal(t, len(patched.(*Record).Rdatas), 0)
+}
+
+type Volume struct {
+	resource.ResourceBase `json:",inline"`
+	Size                  int    `json:"size" rest:"default=10"`
+	Phase                 string `json:"phase" rest:"status,default=pending"`
+	Used                  int    `json:"used" rest:"status,default=1"`
+}
+
+func (v Volume) SupportStatus() bool {
+	return true
+}
+
+func TestDefaultTagWithStatus(t *testing.T) {
+	mgr := NewSchemaManager()
+	mgr.MustImport(&version, Volume{}, &resource.DumbHandler{})
+	url := "/apis/testing/v1/volumes"
+
+	//default values are only filled for spec fields
+	for _, method := range []string{http.MethodPost, http.MethodPut} {
+		u := url
+		if method == http.MethodPut {
+			u += "/v1"
+		}
+		req, _ := http.NewRequest(method, u, bytes.NewBufferString(`{}`))
+		r, err := mgr.CreateResourceFromRequest(req)
+		ut.Assert(t, err == nil, "get err:%v", err)
+		ut.Equal(t, r.(*Volume).Size, 10)
+		ut.Equal(t, r.(*Volume).Phase, "")
+		ut.Equal(t, r.(*Volume).Used, 0)
+	}
+
+	req, _ := http.NewRequest(http.MethodPut, url+"/v1/status", bytes.NewBufferString(`{"phase":"bound"}`))
+	r, err := mgr.CreateResourceFromRequest(req)
+	ut.Assert(t, err == nil, "get err:%v", err)
+	ut.Equal(t, r.(*Volume).Phase, "bound")
+	ut.Equal(t, r.(*Volume).Used, 0)
+	ut.Equal(t, r.(*Volume).Size, 0)
+}
+
+type Host struct {
+	resource.ResourceBase `json:",inline"`
+	Name                  string   `json:"name" rest:"required=true,regex=^[a-z][a-z0-9-]*$"`
+	Addresses             []string `json:"addresses" rest:"isIP"`
+	Mac                   string   `json:"mac" rest:"isMAC"`
+}
+
+func TestValidationErrorCode(t *testing.T) {
+	mgr := NewSchemaManager()
+	mgr.MustImport(&version, Host{}, &resource.DumbHandler{})
+
+	cases := []struct {
+		body string
+		code goresterr.ErrorCode
+	}{
+		{`{"name":"h1","addresses":["10.0.0.1"],"mac":"00:1b:63:84:45:e6"}`, goresterr.ErrorCode{}},
+		{`{"name":"H1"}`, goresterr.PatternMismatch},
+		{`{"name":"h1","addresses":["10.0.0.1","10.0.0"]}`, goresterr.InvalidIP},
+		{`{"name":"h1","mac":"00:1b"}`, goresterr.InvalidMAC},
+		{`{"addresses":["10.0.0.1"]}`, goresterr.MissingRequired},
+	}
+	for _, tc := range cases {
+		req, _ := http.NewRequest(http.MethodPost, "/apis/testing/v1/hosts", bytes.NewBufferString(tc.body))
+		_, err := mgr.CreateResourceFromRequest(req)
+		if tc.code.Code == "" {
+			ut.Assert(t, err == nil, "get err:%v", err)
+		} else {
+			ut.Assert(t, err != nil && err.ErrorCode == tc.code, "%s should fail with %s but get %v", tc.body, tc.code.Code, err)
+		}
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, "/apis/testing/v1/hosts", bytes.NewBufferString(`{"name":"H1","addresses":["10.0.0.1","10.0.0"],"mac":"00:1b"}`))
+	_, err := mgr.CreateResourceFromRequest(req)
+	ut.Assert(t, err != nil, "")
+	ut.Equal(t, err.ErrorCode, goresterr.InvalidBodyContent)
+	var paths, codes []string
+	for _, detail := range err.Details {
+		paths = append(paths, detail.Path)
+		codes = append(codes, detail.Code)
+	}
+	ut.Equal(t, paths, []string{"/name", "/addresses/1", "/mac"})
+	ut.Equal(t, codes, []string{"PatternMismatch", "InvalidIP", "InvalidMAC"})
+}
+
+func TestMalformedBody(t *testing.T) {
+	mgr := NewSchemaManager()
+	mgr.MustImport(&version, Host{}, &resource.DumbHandler{})
+	mgr.MustImport(&version, Cluster{}, &resource.DumbHandler{})
+
+	for url, body := range map[string]string{
+		"/apis/testing/v1/hosts":    `{"name":1}`,
+		"/apis/testing/v1/clusters": `{"Name":1}`,
+	} {
+		for _, data := range []string{body, `["h1"]`} {
+			req, _ := http.NewRequest(http.MethodPost, url, bytes.NewBufferString(data))
+			_, err := mgr.CreateResourceFromRequest(req)
+			ut.Assert(t, err != nil && err.ErrorCode == goresterr.InvalidBodyContent, "%s to %s should be invalid but get %v", data, url, err)
+		}
+	}
 }
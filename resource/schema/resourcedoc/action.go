@@ -15,8 +15,12 @@ type ResourceAction struct {
 }
 
 func genActions(kind resource.ResourceKind) ([]ResourceAction, error) {
+	return buildActions(kind, kind.GetActions())
+}
+
+func buildActions(kind resource.ResourceKind, actions []resource.Action) ([]ResourceAction, error) {
 	resourceActions := make([]ResourceAction, 0)
-	for _, action := range kind.GetActions() {
+	for _, action := range actions {
 		resourceAction := ResourceAction{
 			Name:         action.Name,
 			SubResources: make(map[string]ResourceFields),
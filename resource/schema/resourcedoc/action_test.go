@@ -2,7 +2,9 @@ package resourcedoc
 
 import (
 	"github.com/zdnscloud/gorest/resource"
+	"reflect"
 	"testing"
+	"time"
 
 	ut "github.com/zdnscloud/cement/unittest"
 )
@@ -14,8 +16,8 @@ const (
 
 type Action struct {
 	resource.ResourceBase `json:",inline"`
-	Name                  string `json:"name"`
-	ID                    int    `json:"id"`
+	Name                  string `json:"name" rest:"minLen=1,maxLen=20"`
+	ID                    int    `json:"id" rest:"min=1,max=100"`
 }
 
 type ActionErr struct {
@@ -25,6 +27,7 @@ type ActionErr struct {
 }
 
 type UserPassword struct {
+	Password        string            `json:"password" rest:"required=true,minLen=6,maxLen=32"`
 	SliceStructPtr  []*Struct         `json:"sliceStructPtr"`
 	MapStringInt8   map[string]int8   `json:"mapStringInt8"`
 	MapStringStruct map[string]Struct `json:"mapStringStruct"`
@@ -46,10 +49,16 @@ type Struct2 struct {
 }*/
 
 type LoginInfo struct {
-	Uint32          uint32            `json:"uint32"`
+	Uint32          uint32            `json:"uint32" rest:"min=1,max=100,default=10"`
 	MapStringString map[string]string `json:"mapStringString"`
 	MapStringInt    map[string]int    `json:"mapStringInt"`
 	BoolPtr         *bool             `json:"boolPtr"`
+	Float64         float64           `json:"float64" rest:"min=0.5"`
+	Time            time.Time         `json:"time"`
+	Duration        time.Duration     `json:"duration" rest:"default=1m"`
+	IntPtr          *int              `json:"intPtr"`
+	StringPtr       *string           `json:"stringPtr"`
+	TimePtr         *time.Time        `json:"timePtr"`
 }
 
 func (a Action) GetActions() []resource.Action {
@@ -88,16 +97,23 @@ func TestIngressRuleValidate(t *testing.T) {
 				ResourceAction{
 					Name: "actionLogin",
 					Input: ResourceFields{
+						"password":        ResourceField{Type: "string", Description: []string{"required", "minLen=6", "maxLen=32"}},
 						"mapStringInt8":   ResourceField{Type: "map", KeyType: "string", ValueType: "int"},
 						"mapStringStruct": ResourceField{Type: "map", KeyType: "string", ValueType: "struct"},
 						"structPtr":       ResourceField{Type: "struct"},
 						"sliceStructPtr":  ResourceField{Type: "array", ElemType: "struct"},
 					},
 					Output: ResourceFields{
-						"uint32":          ResourceField{Type: "uint"},
+						"uint32":          ResourceField{Type: "uint", Description: []string{"min=1", "max=100"}, Default: float64(10)},
 						"mapStringString": ResourceField{Type: "map", KeyType: "string", ValueType: "string"},
 						"mapStringInt":    ResourceField{Type: "map", KeyType: "string", ValueType: "int"},
 						"boolPtr":         ResourceField{Type: "bool"},
+						"float64":         ResourceField{Type: "float", Description: []string{"min=0.5"}},
+						"time":            ResourceField{Type: "date"},
+						"duration":        ResourceField{Type: "int", Description: []string{"nanoseconds"}, Default: float64(time.Minute)},
+						"intPtr":          ResourceField{Type: "int"},
+						"stringPtr":       ResourceField{Type: "string"},
+						"timePtr":         ResourceField{Type: "date"},
 					},
 					SubResources: map[string]ResourceFields{
 						"struct2": map[string]ResourceField{
@@ -141,3 +157,14 @@ func TestIngressRuleValidate(t *testing.T) {
 		}
 	}
 }
+
+func TestRangeDescription(t *testing.T) {
+	fields, err := buildResourceFields(make(map[string]ResourceFields), reflect.TypeOf(Action{}))
+	ut.Assert(t, err == nil, "build fields failed:%v", err)
+	ut.Equal(t, fields["name"].Description, []string{"minLen=1", "maxLen=20"})
+	ut.Equal(t, fields["id"].Description, []string{"min=1", "max=100"})
+
+	actions, err := genActions(Action{})
+	ut.Assert(t, err == nil, "build actions failed:%v", err)
+	ut.Equal(t, actions[0].Input["password"].Description, []string{"required", "minLen=6", "maxLen=32"})
+}
@@ -11,12 +11,19 @@ import (
 
 	slice "github.com/zdnscloud/cement/slice"
 	"github.com/zdnscloud/gorest/resource"
+	"github.com/zdnscloud/gorest/resource/schema/resourcefield"
+	"github.com/zdnscloud/gorest/resource/schema/resourcefield/validator"
 	"github.com/zdnscloud/gorest/util"
 )
 
 const (
 	requiredTag    = "required"
 	isDomainTag    = "isDomain"
+	statusTag      = "status"
+	readOnlyTag    = "readonly"
+	immutableTag   = "immutable"
+	writeOnlyTag   = "writeonly"
+	defaultTag     = "default="
 	optionsTag     = "options="
 	descriptionTag = "description="
 	docFileSuffix  = ".json"
@@ -24,42 +31,64 @@ const (
 	ignoreJsonFlag = "inline"
 	ignoreJsonName = "-"
 	supportKeyType = "string"
+	//unit of duration which is documented as int
+	durationDescription = "nanoseconds"
 )
 
+//validators with parameter are documented as they are declared
+var paramTags = []string{"min=", "max=", "minLen=", "maxLen=", "regex="}
+
+//rules between fields are documented as they are declared
+var ruleTags = []string{"gtField=", "gteField=", "ltField=", "lteField=", "eqField=", "neField=", "excludes=", "requiredIf=", "requiredUnless="}
+
+//validators for string format
+var formatTags = []string{"isIP", "isIPv4", "isIPv6", "isCIDR", "isEmail", "isURL", "isMAC"}
+
 type ResourceDocument struct {
 	ResourceType       string                    `json:"resourceType,omitempty"`
 	CollectionName     string                    `json:"collectionName,omitempty"`
 	ParentResources    []string                  `json:"parentResources,omitempty"`
 	GoStructName       string                    `json:"goStructName,omitempty"`
 	SupportAsyncDelete bool                      `json:"supportAsyncDelete"`
+	Singleton          bool                      `json:"singleton,omitempty"`
+	SupportStatus      bool                      `json:"supportStatus,omitempty"`
+	CustomValidation   bool                      `json:"customValidation,omitempty"`
 	ResourceFields     ResourceFields            `json:"resourceFields,omitempty"`
 	SubResources       map[string]ResourceFields `json:"subResources,omitempty"`
 	ResourceMethods    []resource.HttpMethod     `json:"resourceMethods,omitempty"`
 	CollectionMethods  []resource.HttpMethod     `json:"collectionMethods,omitempty"`
 	ResourceActions    []ResourceAction          `json:"resourceActions,omitempty"`
+	CollectionActions  []ResourceAction          `json:"collectionActions,omitempty"`
 }
 
 type ResourceFields map[string]ResourceField
 
 type ResourceField struct {
-	Type        string   `json:"type,omitempty"`
-	ValidValues []string `json:"validValues,omitempty"`
-	ElemType    string   `json:"elemType,omitempty"`
-	KeyType     string   `json:"keyType,omitempty"`
-	ValueType   string   `json:"valueType,omitempty"`
-	Description []string `json:"description,omitempty"`
+	Type        string      `json:"type,omitempty"`
+	ValidValues []string    `json:"validValues,omitempty"`
+	ElemType    string      `json:"elemType,omitempty"`
+	KeyType     string      `json:"keyType,omitempty"`
+	ValueType   string      `json:"valueType,omitempty"`
+	Description []string    `json:"description,omitempty"`
+	ReadOnly    bool        `json:"readOnly,omitempty"`
+	Immutable   bool        `json:"immutable,omitempty"`
+	WriteOnly   bool        `json:"writeOnly,omitempty"`
+	Default     interface{} `json:"default,omitempty"`
 }
 
 func NewResourceDocument(name string, kind resource.ResourceKind, handler resource.Handler, parents []string) (*ResourceDocument, error) {
 	resource := &ResourceDocument{
 		ResourceType:       name,
-		CollectionName:     util.GuessPluralName(name),
+		CollectionName:     resource.DefaultResourceName(kind),
 		ParentResources:    parents,
 		GoStructName:       reflect.TypeOf(kind).Name(),
 		SupportAsyncDelete: kind.SupportAsyncDelete(),
+		Singleton:          kind.IsSingleton(),
+		SupportStatus:      kind.SupportStatus(),
+		CustomValidation:   resource.HasValidator(kind),
 		SubResources:       make(map[string]ResourceFields),
-		ResourceMethods:    resource.GetResourceMethods(handler),
-		CollectionMethods:  resource.GetCollectionMethods(handler),
+		ResourceMethods:    resource.GetKindResourceMethods(kind, handler),
+		CollectionMethods:  resource.GetKindCollectionMethods(kind, handler),
 	}
 	if resourceFields, err := buildResourceFields(resource.SubResources, reflect.TypeOf(kind)); err != nil {
 		return resource, fmt.Errorf("build resource %s failed, %s", name, err.Error())
@@ -71,6 +100,14 @@ func NewResourceDocument(name string, kind resource.ResourceKind, handler resour
 	} else {
 		resource.ResourceActions = resourceActions
 	}
+	if kind.IsSingleton() {
+		resource.CollectionName = ""
+	}
+	if collectionActions, err := buildActions(kind, kind.GetCollectionActions()); err != nil {
+		return resource, fmt.Errorf("parse resource %s collection actions failed, %s", name, err.Error())
+	} else if len(collectionActions) > 0 {
+		resource.CollectionActions = collectionActions
+	}
 	return resource, nil
 }
 
@@ -93,6 +130,9 @@ func (r *ResourceDocument) WriteJsonFile(targetPath string) error {
 func buildResourceFields(subResources map[string]ResourceFields, t reflect.Type) (ResourceFields, error) {
 	resourceFields := make(map[string]ResourceField)
 	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).PkgPath != "" {
+			continue
+		}
 		name := t.Field(i).Name
 		typ := t.Field(i).Type
 		tag := t.Field(i).Tag
@@ -121,9 +161,25 @@ func buildResourceFields(subResources map[string]ResourceFields, t reflect.Type)
 
 func buildResourceField(t reflect.Type, tag reflect.StructTag) (ResourceField, error) {
 	typ, ignore := getIgnoreType(t)
+	restTags := util.SplitRestTag(tag.Get("rest"))
 	resourceField := ResourceField{
 		Type:        typ,
 		Description: parseTag(tag, false),
+		ReadOnly:    slice.SliceIndex(restTags, readOnlyTag) >= 0,
+		Immutable:   slice.SliceIndex(restTags, immutableTag) >= 0,
+		WriteOnly:   slice.SliceIndex(restTags, writeOnlyTag) >= 0,
+	}
+	if k := util.Inspect(t); k == util.Duration || k == util.DurationPtr {
+		resourceField.Description = append(resourceField.Description, durationDescription)
+	}
+	for _, restTag := range restTags {
+		if strings.HasPrefix(restTag, defaultTag) {
+			value, err := resourcefield.ParseDefaultValue(t, strings.TrimPrefix(restTag, defaultTag))
+			if err != nil {
+				return resourceField, err
+			}
+			resourceField.Default = value
+		}
 	}
 	if !ignore {
 		if valueRange := parseTag(tag, true); len(valueRange) > 0 {
@@ -155,7 +211,7 @@ func buildResourceField(t reflect.Type, tag reflect.StructTag) (ResourceField, e
 
 func parseTag(tag reflect.StructTag, isOptions bool) []string {
 	var tags []string
-	restTags := strings.Split(tag.Get("rest"), ",")
+	restTags := util.SplitRestTag(tag.Get("rest"))
 	for _, t := range restTags {
 		if isOptions {
 			if strings.HasPrefix(t, optionsTag) {
@@ -163,7 +219,7 @@ func parseTag(tag reflect.StructTag, isOptions bool) []string {
 				break
 			}
 		} else {
-			if strings.HasPrefix(t, requiredTag) {
+			if strings.HasPrefix(t, requiredTag+"=") {
 				tags = append(tags, requiredTag)
 			}
 			if strings.HasPrefix(t, descriptionTag) {
@@ -172,6 +228,20 @@ func parseTag(tag reflect.StructTag, isOptions bool) []string {
 			if strings.HasPrefix(t, isDomainTag) {
 				tags = append(tags, isDomainTag)
 			}
+			if t == statusTag {
+				tags = append(tags, statusTag)
+			}
+			if name := strings.SplitN(t, "=", 2)[0]; slice.SliceIndex(formatTags, name) >= 0 && t != name+"=false" {
+				tags = append(tags, name)
+			}
+			if name := strings.SplitN(t, "=", 2)[0]; validator.IsCustomTag(name) {
+				tags = append(tags, t)
+			}
+			for _, prefix := range append(paramTags, ruleTags...) {
+				if strings.HasPrefix(t, prefix) {
+					tags = append(tags, t)
+				}
+			}
 		}
 	}
 	return tags
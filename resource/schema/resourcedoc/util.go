@@ -11,15 +11,20 @@ const (
 	Array  = "array"
 	Map    = "map"
 	Enum   = "enum"
+	Date   = "date"
 	Unknow = "unknow"
 )
 
 func getIgnoreType(typ reflect.Type) (string, bool) {
+	if typ.Kind() == reflect.Interface {
+		return "json", true
+	}
+
 	switch typ.Name() {
 	case "RawMessage":
 		return "json", true
 	case "ISOTime":
-		return "date", true
+		return Date, true
 	default:
 		return "", false
 	}
@@ -40,8 +45,15 @@ func getStructType(typ reflect.Type) reflect.Type {
 
 func getType(t reflect.Type) string {
 	switch k := util.Inspect(t); k {
-	case util.String, util.Int, util.Uint, util.Bool:
+	case util.String, util.Int, util.Uint, util.Float, util.Bool:
 		return string(k)
+	case util.Duration:
+		//duration is encoded as integer nanoseconds in json
+		return string(util.Int)
+	case util.Time:
+		return Date
+	case util.IntPtr, util.UintPtr, util.FloatPtr, util.StringPtr, util.TimePtr, util.DurationPtr:
+		return getType(t.Elem())
 	case util.StringIntMap, util.StringStringMap, util.StringUintMap, util.StringStructMap, util.StringStructPtrMap:
 		return Map
 	case util.IntSlice, util.UintSlice, util.BoolSlice, util.StringSlice, util.StructSlice, util.StructPtrSlice:
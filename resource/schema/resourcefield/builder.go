@@ -10,7 +10,8 @@ import (
 )
 
 type FieldBuilder struct {
-	fields []Field
+	fields   []Field
+	ruleTags []ruleTag
 }
 
 func NewBuilder() *FieldBuilder {
@@ -22,7 +23,7 @@ func (b *FieldBuilder) Build(typ reflect.Type) (*structField, error) {
 		return nil, err
 	}
 
-	if len(b.fields) == 0 {
+	if len(b.fields) == 0 && len(b.ruleTags) == 0 {
 		return nil, nil
 	}
 
@@ -31,7 +32,17 @@ func (b *FieldBuilder) Build(typ reflect.Type) (*structField, error) {
 		fields[field.Name()] = field
 	}
 
-	return newStructField(nil, fields), nil
+	sf := newStructField(nil, fields)
+	//fields of embedded struct could be referred by rules
+	indexes := util.JsonFieldIndexes(typ)
+	for _, rt := range b.ruleTags {
+		r, err := newRule(typ, indexes, rt)
+		if err != nil {
+			return nil, err
+		}
+		sf.rules = append(sf.rules, r)
+	}
+	return sf, nil
 }
 
 func (b *FieldBuilder) buildFields(typ reflect.Type) error {
@@ -67,6 +78,15 @@ func (b *FieldBuilder) buildField(sf reflect.StructField) error {
 		return err
 	}
 
+	for _, tag := range util.SplitRestTag(sf.Tag.Get("rest")) {
+		if isRuleTag(tag) {
+			b.ruleTags = append(b.ruleTags, ruleTag{
+				jsonName: fieldJsonName(sf.Name, sf.Tag.Get("json")),
+				tag:      tag,
+			})
+		}
+	}
+
 	if field != nil {
 		return b.addField(field)
 	}
@@ -76,19 +96,19 @@ func (b *FieldBuilder) buildField(sf reflect.StructField) error {
 func (b *FieldBuilder) createField(name string, typ reflect.Type, json, rest string) (Field, error) {
 	kind := util.Inspect(typ)
 	switch kind {
-	case util.Uint, util.Int, util.String, util.Bool:
+	case util.Uint, util.Int, util.Float, util.String, util.Bool, util.Time, util.Duration:
 		if rest == "" {
 			return nil, nil
 		}
-		if restTags := strings.Split(rest, ","); len(restTags) > 0 {
+		if restTags := util.SplitRestTag(rest); len(restTags) > 0 {
 			return b.buildLeafField(name, typ, json, restTags)
 		}
-	case util.StringIntMap, util.StringStringMap, util.StringUintMap, util.IntSlice, util.UintSlice, util.StringSlice:
+	case util.StringIntMap, util.StringStringMap, util.StringUintMap, util.IntSlice, util.UintSlice, util.StringSlice, util.BoolSlice:
 		if rest == "" {
 			return nil, nil
 		}
 
-		restTags := strings.Split(rest, ",")
+		restTags := util.SplitRestTag(rest)
 		if len(restTags) == 0 {
 			return nil, nil
 		}
@@ -98,18 +118,24 @@ func (b *FieldBuilder) createField(name string, typ reflect.Type, json, rest str
 			return nil, err
 		}
 
-		if kind == util.IntSlice || kind == util.UintSlice || kind == util.StringSlice {
+		if kind == util.IntSlice || kind == util.UintSlice || kind == util.StringSlice || kind == util.BoolSlice {
 			return newSliceLeafField(f), nil
 		} else {
 			return newMapLeafField(f), nil
 		}
 	case util.StructPtr:
 		return b.createField(name, typ.Elem(), json, rest)
+	case util.IntPtr, util.UintPtr, util.FloatPtr, util.StringPtr, util.BoolPtr, util.TimePtr, util.DurationPtr:
+		f, err := b.createField(name, typ.Elem(), json, rest)
+		if err != nil || f == nil {
+			return nil, err
+		}
+		return newPtrLeafField(f.(*leafField)), nil
 	case util.StringStructMap, util.StringStructPtrMap, util.StructSlice, util.StructPtrSlice:
 		var self Field
 		var err error
 		if rest != "" {
-			self, err = b.buildLeafField(name, typ, json, strings.Split(rest, ","))
+			self, err = b.buildLeafField(name, typ, json, util.SplitRestTag(rest))
 			if err != nil {
 				return nil, err
 			}
@@ -139,19 +165,33 @@ func (b *FieldBuilder) createField(name string, typ reflect.Type, json, rest str
 			return newSliceStructField(self, inner), nil
 		}
 	case util.Struct:
+		if hasDefaultTag(util.SplitRestTag(rest)) {
+			return nil, fmt.Errorf("default value isn't supported by struct field %s", name)
+		}
+
 		sf, err := NewBuilder().Build(typ)
 		if err != nil {
 			return nil, err
 		}
 
+		//struct without validation still need a field if it's tagged
+		if sf == nil && rest != "" {
+			sf = newStructField(nil, nil)
+		}
+
 		if sf != nil {
 			self := newLeafField(name, fieldJsonName(name, json), typ.Kind())
-			if err := fieldParseOptional(self, typ.Kind(), strings.Split(rest, ",")); err != nil {
+			if err := fieldParseOptional(self, typ.Kind(), util.SplitRestTag(rest)); err != nil {
 				return nil, err
 			}
 			sf.Field = self
 			return sf, nil
 		}
+	default:
+		//field with unsupported kind is only kept to be hidden
+		if hasWriteOnlyTag(util.SplitRestTag(rest)) {
+			return newWriteOnlyField(name, fieldJsonName(name, json), typ.Kind()), nil
+		}
 	}
 	return nil, nil
 }
@@ -168,6 +208,9 @@ func (b *FieldBuilder) buildLeafField(name string, typ reflect.Type, json string
 	if err := fieldParseOptional(field, typ.Kind(), restTags); err != nil {
 		return nil, err
 	}
+	if err := fieldParseDefault(field, typ, restTags); err != nil {
+		return nil, err
+	}
 	return field, nil
 }
 
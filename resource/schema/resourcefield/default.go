@@ -0,0 +1,197 @@
+package resourcefield
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zdnscloud/gorest/util"
+)
+
+const (
+	defaultTag = "default="
+	//separator of slice elements and map entries
+	defaultElemSeparator = "|"
+	//separator of key and value in map entry
+	defaultKeySeparator = ":"
+)
+
+//parse default value in rest tag into json value, eg:
+//int: default=10, slice: default=a|b, map: default=a:1|b:2
+//time is in RFC3339 format, duration is like 1h30m or integer nanoseconds
+func ParseDefaultValue(typ reflect.Type, value string) (interface{}, error) {
+	v, err := parseDefaultValue(typ, value)
+	if err != nil {
+		return nil, err
+	}
+	return toJsonValue(v.Interface())
+}
+
+func parseDefaultValue(typ reflect.Type, value string) (reflect.Value, error) {
+	switch util.Inspect(typ) {
+	case util.Int, util.Uint, util.Float, util.String, util.Bool, util.Time, util.Duration:
+		return parseLeafValue(typ, value)
+	case util.IntPtr, util.UintPtr, util.FloatPtr, util.StringPtr, util.BoolPtr, util.TimePtr, util.DurationPtr:
+		return parseDefaultValue(typ.Elem(), value)
+	case util.IntSlice, util.UintSlice, util.StringSlice, util.BoolSlice:
+		v := reflect.MakeSlice(typ, 0, 0)
+		for _, s := range splitDefaultValue(value) {
+			elem, err := parseLeafValue(typ.Elem(), s)
+			if err != nil {
+				return v, err
+			}
+			v = reflect.Append(v, elem)
+		}
+		return v, nil
+	case util.StringIntMap, util.StringUintMap, util.StringStringMap:
+		v := reflect.MakeMap(typ)
+		for _, s := range splitDefaultValue(value) {
+			kv := strings.SplitN(s, defaultKeySeparator, 2)
+			if len(kv) != 2 {
+				return v, fmt.Errorf("map entry %s has no key", s)
+			}
+			elem, err := parseLeafValue(typ.Elem(), kv[1])
+			if err != nil {
+				return v, err
+			}
+			v.SetMapIndex(reflect.ValueOf(kv[0]).Convert(typ.Key()), elem)
+		}
+		return v, nil
+	default:
+		return reflect.Value{}, fmt.Errorf("default value isn't supported by %v", typ)
+	}
+}
+
+//empty string means empty slice or map
+func splitDefaultValue(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, defaultElemSeparator)
+}
+
+func parseLeafValue(typ reflect.Type, s string) (reflect.Value, error) {
+	v := reflect.New(typ).Elem()
+	switch util.Inspect(typ) {
+	case util.Duration:
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			v.SetInt(i)
+			return v, nil
+		}
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return v, fmt.Errorf("%s isn't valid %v", s, typ)
+		}
+		v.SetInt(int64(d))
+		return v, nil
+	case util.Time:
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return v, fmt.Errorf("%s isn't valid %v", s, typ)
+		}
+		return reflect.ValueOf(t).Convert(typ), nil
+	}
+
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, typ.Bits())
+		if err != nil {
+			return v, fmt.Errorf("%s isn't valid %v", s, typ)
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		i, err := strconv.ParseUint(s, 10, typ.Bits())
+		if err != nil {
+			return v, fmt.Errorf("%s isn't valid %v", s, typ)
+		}
+		v.SetUint(i)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, typ.Bits())
+		if err != nil {
+			return v, fmt.Errorf("%s isn't valid %v", s, typ)
+		}
+		v.SetFloat(f)
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return v, fmt.Errorf("%s isn't valid %v", s, typ)
+		}
+		v.SetBool(b)
+	default:
+		return v, fmt.Errorf("default value isn't supported by %v", typ)
+	}
+	return v, nil
+}
+
+//default value is filled into raw json data, so it should be
+//same with the value unmarshalled from json
+func toJsonValue(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+//default value should pass the validation of the field
+func fieldParseDefault(f *leafField, typ reflect.Type, restTags []string) error {
+	for _, tag := range restTags {
+		if strings.HasPrefix(tag, defaultTag) == false {
+			continue
+		}
+
+		if f.IsRequired() {
+			return fmt.Errorf("required field %s cannot have default value", f.JsonName())
+		}
+		if f.IsReadOnly() {
+			return fmt.Errorf("readonly field %s cannot have default value", f.JsonName())
+		}
+
+		v, err := parseDefaultValue(typ, strings.TrimPrefix(tag, defaultTag))
+		if err != nil {
+			return fmt.Errorf("default value of field %s is invalid:%s", f.JsonName(), err.Error())
+		}
+
+		switch v.Kind() {
+		case reflect.Slice:
+			for i := 0; i < v.Len() && err == nil; i++ {
+				err = f.doValidate(v.Index(i).Interface())
+			}
+		case reflect.Map:
+			iter := v.MapRange()
+			for iter.Next() && err == nil {
+				err = f.doValidate(iter.Value().Interface())
+			}
+		default:
+			err = f.doValidate(v.Interface())
+		}
+		if err != nil {
+			return fmt.Errorf("default value of field %s is invalid:%s", f.JsonName(), err.Error())
+		}
+
+		value, err := toJsonValue(v.Interface())
+		if err != nil {
+			return err
+		}
+		f.SetDefault(value)
+	}
+	return nil
+}
+
+func hasDefaultTag(restTags []string) bool {
+	for _, tag := range restTags {
+		if strings.HasPrefix(tag, defaultTag) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package resourcefield
+
+import (
+	"strings"
+
+	goresterr "github.com/zdnscloud/gorest/error"
+)
+
+//errors collected in validation, path of each error is the json
+//pointer relative to the json data which is validated
+type ValidationErrors []goresterr.ErrorDetail
+
+func (errs ValidationErrors) Error() string {
+	var messages []string
+	for _, e := range errs {
+		messages = append(messages, e.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+//api error has the same code with the detail if there is only one
+func (errs ValidationErrors) APIError() *goresterr.APIError {
+	code := goresterr.InvalidBodyContent
+	if len(errs) == 1 {
+		code = errs[0].ErrorCode
+	}
+	apiErr := goresterr.NewAPIError(code, errs.Error())
+	apiErr.Details = errs
+	return apiErr
+}
+
+//add err of the json data with path, paths of the collected errors
+//in err are prefixed with path, error without specific code is
+//treated as invalid body content
+func (errs *ValidationErrors) add(path string, err error) {
+	switch e := err.(type) {
+	case nil:
+	case ValidationErrors:
+		for _, detail := range e {
+			detail.Path = path + detail.Path
+			*errs = append(*errs, detail)
+		}
+	case *goresterr.APIError:
+		*errs = append(*errs, goresterr.ErrorDetail{
+			ErrorCode: e.ErrorCode,
+			Path:      path,
+			Message:   e.Message,
+		})
+	default:
+		*errs = append(*errs, goresterr.ErrorDetail{
+			ErrorCode: goresterr.InvalidBodyContent,
+			Path:      path,
+			Message:   err.Error(),
+		})
+	}
+}
+
+//nil slice should be returned as nil error
+func (errs ValidationErrors) err() error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
@@ -3,8 +3,12 @@ package resourcefield
 import (
 	"fmt"
 	"reflect"
+	"sort"
+	"strconv"
 
+	goresterr "github.com/zdnscloud/gorest/error"
 	"github.com/zdnscloud/gorest/resource/schema/resourcefield/validator"
+	"github.com/zdnscloud/gorest/util"
 )
 
 type Field interface {
@@ -14,6 +18,32 @@ type Field interface {
 	IsRequired() bool
 	SetRequired(bool)
 
+	//status field is only updated through status subresource
+	IsStatus() bool
+	SetStatus(bool)
+
+	//readonly field is ignored in create and update
+	IsReadOnly() bool
+	SetReadOnly(bool)
+
+	//immutable field can't be changed by update once created
+	IsImmutable() bool
+	SetImmutable(bool)
+
+	//writeonly field is accepted from client but never returned
+	IsWriteOnly() bool
+	SetWriteOnly(bool)
+
+	//whether the field or any field nested in it is writeonly
+	HasWriteOnly() bool
+	//remove writeonly field from raw json data, fields nested in
+	//struct, slice and map are removed recursively
+	HideWriteOnly(raw map[string]interface{})
+
+	//fill default value into raw json data if the field isn't specified,
+	//return true if any value is filled
+	FillDefault(raw map[string]interface{}) bool
+
 	//validate fields of go struct
 	//resource should be unmarshalled from raw
 	Validate(resource interface{}, raw map[string]interface{}) error
@@ -21,6 +51,8 @@ type Field interface {
 
 var _ Field = &leafField{}
 var _ Field = &structField{}
+var _ Field = &ptrLeafField{}
+var _ Field = &writeOnlyField{}
 var _ Field = &sliceLeafField{}
 var _ Field = &sliceStructField{}
 var _ Field = &mapLeafField{}
@@ -32,7 +64,13 @@ type leafField struct {
 	jsonName   string
 	kind       reflect.Kind
 	required   bool
+	status     bool
+	readOnly   bool
+	immutable  bool
+	writeOnly  bool
 	validators []validator.Validator
+	//json value, nil means no default value
+	defaultValue interface{}
 }
 
 func newLeafField(name, jsonName string, kind reflect.Kind) *leafField {
@@ -60,6 +98,63 @@ func (f *leafField) SetRequired(required bool) {
 	f.required = required
 }
 
+func (f *leafField) IsStatus() bool {
+	return f.status
+}
+
+func (f *leafField) SetStatus(status bool) {
+	f.status = status
+}
+
+func (f *leafField) IsReadOnly() bool {
+	return f.readOnly
+}
+
+func (f *leafField) SetReadOnly(readOnly bool) {
+	f.readOnly = readOnly
+}
+
+func (f *leafField) IsImmutable() bool {
+	return f.immutable
+}
+
+func (f *leafField) SetImmutable(immutable bool) {
+	f.immutable = immutable
+}
+
+func (f *leafField) IsWriteOnly() bool {
+	return f.writeOnly
+}
+
+func (f *leafField) SetWriteOnly(writeOnly bool) {
+	f.writeOnly = writeOnly
+}
+
+func (f *leafField) HasWriteOnly() bool {
+	return f.writeOnly
+}
+
+func (f *leafField) HideWriteOnly(raw map[string]interface{}) {
+	if f.writeOnly {
+		delete(raw, f.jsonName)
+	}
+}
+
+func (f *leafField) SetDefault(value interface{}) {
+	f.defaultValue = value
+}
+
+func (f *leafField) FillDefault(raw map[string]interface{}) bool {
+	if f.defaultValue == nil {
+		return false
+	}
+	if _, ok := raw[f.jsonName]; ok {
+		return false
+	}
+	raw[f.jsonName] = f.defaultValue
+	return true
+}
+
 func (f *leafField) SetValidators(validators []validator.Validator) {
 	f.validators = validators
 }
@@ -67,25 +162,71 @@ func (f *leafField) SetValidators(validators []validator.Validator) {
 func (f *leafField) Validate(val interface{}, raw map[string]interface{}) error {
 	if _, ok := raw[f.JsonName()]; !ok {
 		if f.IsRequired() {
-			return fmt.Errorf("field %s is missing", f.jsonName)
+			return newFieldError(f, goresterr.MissingRequired, fmt.Sprintf("field %s is missing", f.jsonName))
 		} else {
 			return nil
 		}
 	}
 
 	if reflect.ValueOf(val).Kind() != f.kind {
-		return fmt.Errorf("field %s has invalid invalid kind", f.jsonName)
+		return newFieldError(f, goresterr.InvalidType, fmt.Sprintf("field %s has invalid invalid kind", f.jsonName))
 	}
 
-	return f.doValidate(val)
+	var errs ValidationErrors
+	errs.add(util.JSONPointer(f.jsonName), f.doValidate(val))
+	return errs.err()
 }
 
+//errors of all the validators are collected
 func (f *leafField) doValidate(val interface{}) error {
+	var errs ValidationErrors
 	for _, validator := range f.validators {
-		if err := validator.Validate(val); err != nil {
-			return err
+		errs.add("", validator.Validate(val))
+	}
+	return errs.err()
+}
+
+//nullable field, null in json data is same with not specified
+type ptrLeafField struct {
+	*leafField
+}
+
+func newPtrLeafField(inner *leafField) *ptrLeafField {
+	return &ptrLeafField{
+		leafField: inner,
+	}
+}
+
+func (f *ptrLeafField) Validate(val interface{}, raw map[string]interface{}) error {
+	if raw[f.JsonName()] == nil {
+		if f.IsRequired() {
+			return newFieldError(f, goresterr.MissingRequired, fmt.Sprintf("field %s is missing", f.JsonName()))
 		}
+		return nil
+	}
+
+	value := reflect.ValueOf(val)
+	if value.Kind() != reflect.Ptr || value.IsNil() {
+		return newFieldError(f, goresterr.InvalidBodyContent, fmt.Sprintf("runtime value of %s isn't synchronize with json data", f.JsonName()))
 	}
+	return f.leafField.Validate(value.Elem().Interface(), raw)
+}
+
+//field whose kind isn't supported by validation, it's only hidden
+//from the response
+type writeOnlyField struct {
+	*leafField
+}
+
+func newWriteOnlyField(name, jsonName string, kind reflect.Kind) *writeOnlyField {
+	f := newLeafField(name, jsonName, kind)
+	f.SetWriteOnly(true)
+	return &writeOnlyField{
+		leafField: f,
+	}
+}
+
+func (f *writeOnlyField) Validate(val interface{}, raw map[string]interface{}) error {
 	return nil
 }
 
@@ -107,16 +248,15 @@ func (f *sliceLeafField) Validate(val interface{}, raw map[string]interface{}) e
 
 	value := reflect.ValueOf(val)
 	if value.Kind() != reflect.Slice {
-		return fmt.Errorf("runtime value of %s isn't synchronize with json data", f.leafField.JsonName())
+		return newFieldError(f, goresterr.InvalidBodyContent, fmt.Sprintf("runtime value of %s isn't synchronize with json data", f.leafField.JsonName()))
 	}
+	var errs ValidationErrors
 	if specified {
 		for i := 0; i < value.Len(); i++ {
-			if err := f.leafField.doValidate(value.Index(i).Interface()); err != nil {
-				return err
-			}
+			errs.add(util.JSONPointer(f.leafField.JsonName(), strconv.Itoa(i)), f.leafField.doValidate(value.Index(i).Interface()))
 		}
 	}
-	return nil
+	return errs.err()
 }
 
 func fieldIsSpecifiedWithKind(f Field, raw map[string]interface{}, kind reflect.Kind) (bool, interface{}, error) {
@@ -128,27 +268,46 @@ func fieldIsSpecifiedWithKind(f Field, raw map[string]interface{}, kind reflect.
 
 	if f.IsRequired() {
 		if !specified {
-			return specified, nil, fmt.Errorf("field %s is missing", f.JsonName())
+			return specified, nil, newFieldError(f, goresterr.MissingRequired, fmt.Sprintf("field %s is missing", f.JsonName()))
 		}
 	}
 
 	if specified {
 		v := reflect.ValueOf(jsonVal)
 		if !v.IsValid() {
-			return specified, nil, fmt.Errorf("field %s has invalid value", f.JsonName())
+			return specified, nil, newFieldError(f, goresterr.InvalidType, fmt.Sprintf("field %s has invalid value", f.JsonName()))
 		}
 
 		if v.Kind() != kind {
-			return specified, nil, fmt.Errorf("field %s isn't %v", f.JsonName(), kind)
+			return specified, nil, newFieldError(f, goresterr.InvalidType, fmt.Sprintf("field %s isn't %v", f.JsonName(), kind))
 		}
 
 		if v.Len() == 0 && f.IsRequired() {
-			return specified, nil, fmt.Errorf("field %s with empty slice ", f.JsonName())
+			return specified, nil, newFieldError(f, goresterr.MissingRequired, fmt.Sprintf("field %s with empty slice ", f.JsonName()))
 		}
 	}
 	return specified, jsonVal, nil
 }
 
+//error of the field, path is relative to the struct which the field belongs to
+func newFieldError(f Field, code goresterr.ErrorCode, message string) error {
+	return ValidationErrors{goresterr.ErrorDetail{
+		ErrorCode: code,
+		Path:      util.JSONPointer(f.JsonName()),
+		Message:   message,
+	}}
+}
+
+//keys of json map are sorted to make the order of errors stable
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 type sliceStructField struct {
 	Field
 	inner *structField
@@ -174,21 +333,57 @@ func (f *sliceStructField) Validate(val interface{}, raw map[string]interface{})
 	value := reflect.ValueOf(val)
 	jsonValue := reflect.ValueOf(jsonVal)
 	if value.Kind() != reflect.Slice || value.Len() != jsonValue.Len() {
-		return fmt.Errorf("runtime value of %s isn't synchronize with json data", f.Field.JsonName())
+		return newFieldError(f, goresterr.InvalidBodyContent, fmt.Sprintf("runtime value of %s isn't synchronize with json data", f.Field.JsonName()))
 	}
 
+	var errs ValidationErrors
 	for i := 0; i < value.Len(); i++ {
+		path := util.JSONPointer(f.Field.JsonName(), strconv.Itoa(i))
 		elemVal := jsonValue.Index(i).Interface()
 		elemRaw, ok := elemVal.(map[string]interface{})
 		if !ok {
-			return fmt.Errorf("elem of field %s is not a struct", f.Field.JsonName())
+			errs.add(path, goresterr.NewAPIError(goresterr.InvalidType, fmt.Sprintf("elem of field %s is not a struct", f.Field.JsonName())))
+			continue
 		}
-		if err := f.inner.Validate(value.Index(i).Interface(), elemRaw); err != nil {
-			return err
+		errs.add(path, f.inner.Validate(value.Index(i).Interface(), elemRaw))
+	}
+	return errs.err()
+}
+
+func (f *sliceStructField) FillDefault(raw map[string]interface{}) bool {
+	elems, ok := raw[f.Field.JsonName()].([]interface{})
+	if ok == false || f.inner == nil {
+		return false
+	}
+
+	filled := false
+	for _, elem := range elems {
+		if elemRaw, ok := elem.(map[string]interface{}); ok && f.inner.FillDefault(elemRaw) {
+			filled = true
 		}
 	}
+	return filled
+}
 
-	return nil
+func (f *sliceStructField) HasWriteOnly() bool {
+	return f.Field.IsWriteOnly() || (f.inner != nil && f.inner.HasWriteOnly())
+}
+
+func (f *sliceStructField) HideWriteOnly(raw map[string]interface{}) {
+	if f.Field.IsWriteOnly() {
+		delete(raw, f.Field.JsonName())
+		return
+	}
+
+	elems, ok := raw[f.Field.JsonName()].([]interface{})
+	if ok == false || f.inner == nil {
+		return
+	}
+	for _, elem := range elems {
+		if elemRaw, ok := elem.(map[string]interface{}); ok {
+			f.inner.HideWriteOnly(elemRaw)
+		}
+	}
 }
 
 type mapLeafField struct {
@@ -202,7 +397,7 @@ func newMapLeafField(inner *leafField) *mapLeafField {
 }
 
 func (f *mapLeafField) Validate(val interface{}, raw map[string]interface{}) error {
-	specified, _, err := fieldIsSpecifiedWithKind(f.leafField, raw, reflect.Map)
+	specified, jsonVal, err := fieldIsSpecifiedWithKind(f.leafField, raw, reflect.Map)
 	if err != nil {
 		return err
 	}
@@ -211,16 +406,19 @@ func (f *mapLeafField) Validate(val interface{}, raw map[string]interface{}) err
 	}
 
 	value := reflect.ValueOf(val)
-	if value.Kind() != reflect.Map {
-		return fmt.Errorf("runtime value of %s isn't synchronize with json data", f.leafField.JsonName())
+	jsonMap, ok := jsonVal.(map[string]interface{})
+	if value.Kind() != reflect.Map || ok == false {
+		return newFieldError(f, goresterr.InvalidBodyContent, fmt.Sprintf("runtime value of %s isn't synchronize with json data", f.leafField.JsonName()))
 	}
-	iter := value.MapRange()
-	for iter.Next() {
-		if err := f.leafField.doValidate(iter.Value().Interface()); err != nil {
-			return err
+	var errs ValidationErrors
+	for _, key := range sortedKeys(jsonMap) {
+		elem := value.MapIndex(reflect.ValueOf(key).Convert(value.Type().Key()))
+		if elem.IsValid() == false {
+			return newFieldError(f, goresterr.InvalidBodyContent, fmt.Sprintf("runtime value of %s isn't synchronize with json data", f.leafField.JsonName()))
 		}
+		errs.add(util.JSONPointer(f.leafField.JsonName(), key), f.leafField.doValidate(elem.Interface()))
 	}
-	return nil
+	return errs.err()
 }
 
 type mapStructField struct {
@@ -245,29 +443,69 @@ func (f *mapStructField) Validate(val interface{}, raw map[string]interface{}) e
 		return nil
 	}
 
-	jsonValue := reflect.ValueOf(jsonVal)
 	value := reflect.ValueOf(val)
-	if value.Kind() != reflect.Map || jsonValue.Len() != value.Len() {
-		return fmt.Errorf("runtime value of %s isn't synchronize with json data", f.Field.JsonName())
+	jsonMap, ok := jsonVal.(map[string]interface{})
+	if value.Kind() != reflect.Map || ok == false || len(jsonMap) != value.Len() {
+		return newFieldError(f, goresterr.InvalidBodyContent, fmt.Sprintf("runtime value of %s isn't synchronize with json data", f.Field.JsonName()))
 	}
 
-	ji := jsonValue.MapRange()
-	vi := value.MapRange()
-	for ji.Next() && vi.Next() {
-		elemRaw, ok := (ji.Value().Interface()).(map[string]interface{})
+	var errs ValidationErrors
+	for _, key := range sortedKeys(jsonMap) {
+		path := util.JSONPointer(f.Field.JsonName(), key)
+		elem := value.MapIndex(reflect.ValueOf(key).Convert(value.Type().Key()))
+		if elem.IsValid() == false {
+			return newFieldError(f, goresterr.InvalidBodyContent, fmt.Sprintf("runtime value of %s isn't synchronize with json data", f.Field.JsonName()))
+		}
+		elemRaw, ok := jsonMap[key].(map[string]interface{})
 		if !ok {
-			return fmt.Errorf("value of field %s is not a struct", f.Field.JsonName())
+			errs.add(path, goresterr.NewAPIError(goresterr.InvalidType, fmt.Sprintf("value of field %s is not a struct", f.Field.JsonName())))
+			continue
 		}
-		if err := f.inner.Validate(vi.Value().Interface(), elemRaw); err != nil {
-			return err
+		errs.add(path, f.inner.Validate(elem.Interface(), elemRaw))
+	}
+	return errs.err()
+}
+
+func (f *mapStructField) FillDefault(raw map[string]interface{}) bool {
+	elems, ok := raw[f.Field.JsonName()].(map[string]interface{})
+	if ok == false || f.inner == nil {
+		return false
+	}
+
+	filled := false
+	for _, elem := range elems {
+		if elemRaw, ok := elem.(map[string]interface{}); ok && f.inner.FillDefault(elemRaw) {
+			filled = true
+		}
+	}
+	return filled
+}
+
+func (f *mapStructField) HasWriteOnly() bool {
+	return f.Field.IsWriteOnly() || (f.inner != nil && f.inner.HasWriteOnly())
+}
+
+func (f *mapStructField) HideWriteOnly(raw map[string]interface{}) {
+	if f.Field.IsWriteOnly() {
+		delete(raw, f.Field.JsonName())
+		return
+	}
+
+	elems, ok := raw[f.Field.JsonName()].(map[string]interface{})
+	if ok == false || f.inner == nil {
+		return
+	}
+	for _, elem := range elems {
+		if elemRaw, ok := elem.(map[string]interface{}); ok {
+			f.inner.HideWriteOnly(elemRaw)
 		}
 	}
-	return nil
 }
 
 type structField struct {
 	Field
 	fields map[string]Field
+	rules  []rule
 }
 
 func newStructField(self Field, fields map[string]Field) *structField {
@@ -287,20 +525,88 @@ func (f *structField) Validate(val interface{}, raw map[string]interface{}) erro
 		}
 
 		if f.Field.IsRequired() && !hasField {
-			return fmt.Errorf("struct field %s is missing", jsonName)
+			return newFieldError(f.Field, goresterr.MissingRequired, fmt.Sprintf("struct field %s is missing", jsonName))
 		}
 		//field isn't speicifed
 		if !hasField {
 			return nil
 		}
 
-		if nr, ok := jsonVal.(map[string]interface{}); ok {
-			raw = nr
-		} else {
-			return fmt.Errorf("value of field %s in json data is not a struct", jsonName)
+		nr, ok := jsonVal.(map[string]interface{})
+		if ok == false {
+			return newFieldError(f.Field, goresterr.InvalidType, fmt.Sprintf("value of field %s in json data is not a struct", jsonName))
+		}
+
+		var errs ValidationErrors
+		errs.add(util.JSONPointer(jsonName), f.validate(val, nr))
+		return errs.err()
+	}
+
+	return f.validate(val, raw)
+}
+
+//rules are checked after the fields are validated
+func (f *structField) validate(val interface{}, raw map[string]interface{}) error {
+	var errs ValidationErrors
+	errs.add("", f.validateFields(val, raw, nil))
+	errs.add("", f.validateRules(val, raw, nil, false))
+	return errs.err()
+}
+
+func (f *structField) FillDefault(raw map[string]interface{}) bool {
+	//this is a nest struct
+	if f.Field != nil {
+		nested, ok := raw[f.Field.JsonName()].(map[string]interface{})
+		if ok == false {
+			return false
+		}
+		raw = nested
+	}
+
+	filled := false
+	for _, field := range f.fields {
+		if field.FillDefault(raw) {
+			filled = true
+		}
+	}
+	return filled
+}
+
+func (f *structField) HasWriteOnly() bool {
+	//this is a nest struct
+	if f.Field != nil && f.Field.IsWriteOnly() {
+		return true
+	}
+
+	for _, field := range f.fields {
+		if field.HasWriteOnly() {
+			return true
 		}
 	}
+	return false
+}
+
+func (f *structField) HideWriteOnly(raw map[string]interface{}) {
+	//this is a nest struct
+	if f.Field != nil {
+		if f.Field.IsWriteOnly() {
+			delete(raw, f.Field.JsonName())
+			return
+		}
+		nested, ok := raw[f.Field.JsonName()].(map[string]interface{})
+		if ok == false {
+			return
+		}
+		raw = nested
+	}
+
+	for _, field := range f.fields {
+		field.HideWriteOnly(raw)
+	}
+}
 
+//selected is nil means validate all the fields
+func (f *structField) validateFields(val interface{}, raw map[string]interface{}, selected map[string]struct{}) error {
 	value := reflect.ValueOf(val)
 	//only handle one level redirect
 	if value.Kind() == reflect.Ptr {
@@ -309,6 +615,7 @@ func (f *structField) Validate(val interface{}, raw map[string]interface{}) erro
 	if value.Kind() != reflect.Struct {
 		return fmt.Errorf("struct field with non-sturct but %v", value.Kind())
 	}
+	var errs ValidationErrors
 	typ := value.Type()
 	for i := 0; i < typ.NumField(); i++ {
 		ft := typ.Field(i)
@@ -318,17 +625,42 @@ func (f *structField) Validate(val interface{}, raw map[string]interface{}) erro
 		}
 
 		if ft.Anonymous {
-			if err := f.Validate(value.Field(i).Interface(), raw); err != nil {
-				return err
-			}
+			errs.add("", f.validateFields(value.Field(i).Interface(), raw, selected))
 			continue
 		}
 
 		if field, ok := f.fields[ft.Name]; ok {
-			if err := field.Validate(value.Field(i).Interface(), raw); err != nil {
-				return err
+			if selected != nil {
+				if _, ok := selected[field.JsonName()]; !ok {
+					continue
+				}
 			}
+			errs.add("", field.Validate(value.Field(i).Interface(), raw))
 		}
 	}
-	return nil
+	return errs.err()
+}
+
+//error of the rule is reported on the field which declares it,
+//selected is nil means check all the rules
+func (f *structField) validateRules(val interface{}, raw map[string]interface{}, selected func(rule) bool, marshalled bool) error {
+	if len(f.rules) == 0 {
+		return nil
+	}
+
+	value := reflect.ValueOf(val)
+	if value.Kind() == reflect.Ptr {
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return fmt.Errorf("struct field with non-sturct but %v", value.Kind())
+	}
+	var errs ValidationErrors
+	for _, r := range f.rules {
+		if selected != nil && selected(r) == false {
+			continue
+		}
+		errs.add(util.JSONPointer(r.fieldNames()[0]), r.check(value, raw, marshalled))
+	}
+	return errs.err()
 }
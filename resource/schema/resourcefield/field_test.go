@@ -3,9 +3,11 @@ package resourcefield
 import (
 	"encoding/json"
 	ut "github.com/zdnscloud/cement/unittest"
+	goresterr "github.com/zdnscloud/gorest/error"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestFieldBuild(t *testing.T) {
@@ -424,3 +426,411 @@ func TestPointerNestedStruct(t *testing.T) {
 	err = sf.Validate(storage, raw)
 	ut.Assert(t, err != nil, "lvm is missing")
 }
+
+func TestStatusFields(t *testing.T) {
+	type Condition struct {
+		Reason string `json:"reason"`
+	}
+
+	type TestStruct struct {
+		Name       string    `json:"name" rest:"required=true"`
+		Phase      string    `json:"phase" rest:"status,options=pending|running"`
+		Ready      []string  `json:"ready" rest:"status"`
+		Condition  Condition `json:"condition" rest:"status"`
+		Generation int       `json:"generation"`
+	}
+
+	rf, err := New(reflect.TypeOf(TestStruct{}))
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, rf.StatusFields(), []string{"condition", "phase", "ready"})
+	ut.Equal(t, rf.SpecFields(), []string{"name"})
+
+	raw := map[string]interface{}{"phase": "running"}
+	ut.Assert(t, rf.ValidateFields(TestStruct{Phase: "running"}, raw, rf.StatusFields()) == nil, "")
+	ut.Assert(t, rf.ValidateFields(TestStruct{Phase: "running"}, raw, rf.SpecFields()) != nil, "")
+}
+
+func TestRegexWithComma(t *testing.T) {
+	type TestStruct struct {
+		Name string `json:"name" rest:"required=true,regex=^[a-z]{1\\,3}$"`
+	}
+
+	rf, err := New(reflect.TypeOf(TestStruct{}))
+	ut.Assert(t, err == nil, "build fields failed:%v", err)
+	cases := []struct {
+		raw   string
+		valid bool
+	}{
+		{`{"name":"abc"}`, true},
+		{`{"name":"abcd"}`, false},
+		{`{}`, false},
+	}
+	for _, tc := range cases {
+		var s TestStruct
+		raw := make(map[string]interface{})
+		json.Unmarshal([]byte(tc.raw), &s)
+		json.Unmarshal([]byte(tc.raw), &raw)
+		err := rf.Validate(&s, raw)
+		ut.Equal(t, err == nil, tc.valid)
+	}
+}
+
+func TestAccessFields(t *testing.T) {
+	type TestStruct struct {
+		Name    string `json:"name" rest:"required=true,immutable"`
+		Counter int    `json:"counter" rest:"readonly"`
+	}
+
+	rf, err := New(reflect.TypeOf(TestStruct{}))
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, rf.ImmutableFields(), []string{"name"})
+	ut.Equal(t, rf.ReadOnlyFields(), []string{"counter"})
+
+	type InvalidStruct struct {
+		Counter int `json:"counter" rest:"readonly,required=true"`
+	}
+	_, err = New(reflect.TypeOf(InvalidStruct{}))
+	ut.Assert(t, err != nil, "readonly field shouldn't be required")
+}
+
+func TestHideWriteOnlyFields(t *testing.T) {
+	type Credential struct {
+		User     string `json:"user"`
+		Password string `json:"password" rest:"writeonly"`
+	}
+
+	type TestStruct struct {
+		Name        string                 `json:"name" rest:"required=true"`
+		Token       string                 `json:"token" rest:"writeonly"`
+		Extra       interface{}            `json:"extra" rest:"writeonly"`
+		Credential  Credential             `json:"credential"`
+		Credentials []*Credential          `json:"credentials"`
+		Users       map[string]Credential  `json:"users"`
+		Secrets     map[string]*Credential `json:"secrets" rest:"writeonly"`
+	}
+
+	rf, err := New(reflect.TypeOf(TestStruct{}))
+	ut.Assert(t, err == nil, "")
+	ut.Assert(t, rf.HasWriteOnlyFields(), "")
+	ut.Equal(t, rf.WriteOnlyFields(), []string{"extra", "secrets", "token"})
+
+	raw := make(map[string]interface{})
+	json.Unmarshal([]byte(`{"name":"ben","token":"t","extra":{"a":1},
+		"credential":{"user":"ben","password":"p1"},
+		"credentials":[{"user":"ben","password":"p2"},{"user":"bob"}],
+		"users":{"ben":{"user":"ben","password":"p3"}},
+		"secrets":{"ben":{"user":"ben","password":"p4"}}}`), &raw)
+	rf.HideWriteOnlyFields(raw)
+	data, _ := json.Marshal(raw)
+	ut.Equal(t, string(data), `{"credential":{"user":"ben"},"credentials":[{"user":"ben"},{"user":"bob"}],"name":"ben","users":{"ben":{"user":"ben"}}}`)
+
+	type NestedStruct struct {
+		Name       string     `json:"name" rest:"required=true"`
+		Credential Credential `json:"credential" rest:"required=true"`
+	}
+	rf, err = New(reflect.TypeOf(NestedStruct{}))
+	ut.Assert(t, err == nil, "")
+	ut.Assert(t, rf.HasWriteOnlyFields(), "nested writeonly field should be found")
+}
+
+func TestFillDefault(t *testing.T) {
+	type Port struct {
+		Protocol string `json:"protocol" rest:"default=tcp,options=tcp|udp"`
+		Port     int    `json:"port"`
+	}
+
+	type TestStruct struct {
+		Replicas int               `json:"replicas" rest:"default=2,min=1,max=10"`
+		Enabled  bool              `json:"enabled" rest:"default=true"`
+		Hosts    []string          `json:"hosts" rest:"default=a|b"`
+		Labels   map[string]uint16 `json:"labels" rest:"default=a:1|b:2"`
+		Ports    []Port            `json:"ports"`
+	}
+
+	rf, err := New(reflect.TypeOf(TestStruct{}))
+	ut.Assert(t, err == nil, "")
+
+	raw := map[string]interface{}{
+		"replicas": float64(3),
+		"labels":   map[string]interface{}{"c": float64(3)},
+		"ports":    []interface{}{map[string]interface{}{"port": float64(80)}},
+	}
+	ut.Assert(t, rf.FillDefaults(raw), "")
+	data, _ := json.Marshal(raw)
+	ut.Equal(t, string(data), `{"enabled":true,"hosts":["a","b"],"labels":{"c":3},"ports":[{"port":80,"protocol":"tcp"}],"replicas":3}`)
+	ut.Assert(t, rf.FillDefaults(raw) == false, "")
+
+	invalidTypes := []interface{}{
+		struct {
+			Replicas int8 `rest:"default=1000"`
+		}{},
+		struct {
+			Replicas int `rest:"default=20,min=1,max=10"`
+		}{},
+		struct {
+			Enabled bool `rest:"default=yes"`
+		}{},
+		struct {
+			Mode string `rest:"default=lvm,options=ceph|nfs"`
+		}{},
+		struct {
+			Labels map[string]int `rest:"default=a"`
+		}{},
+		struct {
+			Port Port `rest:"default=80"`
+		}{},
+		struct {
+			Name string `rest:"required=true,default=a"`
+		}{},
+	}
+	for _, typ := range invalidTypes {
+		_, err := New(reflect.TypeOf(typ))
+		ut.Assert(t, err != nil, "default of %v should be invalid", typ)
+	}
+}
+
+func TestFieldRules(t *testing.T) {
+	type Range struct {
+		Start int `json:"start"`
+		End   int `json:"end" rest:"gteField=start"`
+	}
+
+	type Listener struct {
+		StartIP  string `json:"startIP" rest:"isIP=true"`
+		EndIP    string `json:"endIP" rest:"isIP=true,gtField=startIP"`
+		Protocol string `json:"protocol" rest:"options=http|https|wss"`
+		TLSCert  string `json:"tlsCert" rest:"requiredIf=protocol:https|wss"`
+		Port     *int   `json:"port" rest:"requiredUnless=protocol:http"`
+		Token    string `json:"token" rest:"excludes=password|key"`
+		Password string `json:"password"`
+		Key      string `json:"key"`
+		Range    *Range `json:"range"`
+		MinConn  int    `json:"minConn"`
+		MaxConn  *int   `json:"maxConn" rest:"gtField=minConn"`
+		Mode     string `json:"mode"`
+		Enabled  bool   `json:"enabled" rest:"requiredIf=mode:ha"`
+		Backup   int    `json:"backup" rest:"requiredIf=mode:ha"`
+	}
+
+	rf, err := New(reflect.TypeOf(Listener{}))
+	ut.Assert(t, err == nil, "build rules failed:%v", err)
+
+	cases := []struct {
+		raw      string
+		errorMsg string
+	}{
+		{`{"startIP":"10.0.0.2","endIP":"10.0.0.10","protocol":"http"}`, ""},
+		{`{"startIP":"10.0.0.10","endIP":"10.0.0.2","protocol":"http"}`, "field endIP should be greater than field startIP"},
+		{`{"startIP":"2001::1","endIP":"2001::1","protocol":"http"}`, "field endIP should be greater than field startIP"},
+		{`{"endIP":"10.0.0.2","protocol":"http"}`, ""},
+		{`{"protocol":"https","tlsCert":"cert","port":443}`, ""},
+		{`{"protocol":"https","port":443}`, "field tlsCert is required when field protocol is https or wss"},
+		{`{"protocol":"wss","tlsCert":"cert"}`, "field port is required unless field protocol is http"},
+		{`{"protocol":"http","token":"abc","password":"123"}`, "field token and field password are mutually exclusive"},
+		{`{"protocol":"http","token":"abc","key":null}`, ""},
+		{`{"protocol":"http","range":{"start":10,"end":10}}`, ""},
+		{`{"protocol":"http","range":{"start":10,"end":1}}`, "field end should be greater than or equal to field start"},
+		{`{"protocol":"http","minConn":1,"maxConn":5}`, ""},
+		{`{"protocol":"http","minConn":5,"maxConn":1}`, "field maxConn should be greater than field minConn"},
+		{`{"protocol":"http","mode":"ha","enabled":false,"backup":0}`, ""},
+		{`{"protocol":"http","mode":"ha","enabled":false}`, "field backup is required when field mode is ha"},
+		{`{"protocol":"http","token":"abc","password":""}`, "field token and field password are mutually exclusive"},
+	}
+	for _, tc := range cases {
+		var l Listener
+		raw := make(map[string]interface{})
+		ut.Assert(t, json.Unmarshal([]byte(tc.raw), &l) == nil, "")
+		ut.Assert(t, json.Unmarshal([]byte(tc.raw), &raw) == nil, "")
+		err := rf.Validate(&l, raw)
+		if tc.errorMsg == "" {
+			ut.Assert(t, err == nil, "%s should be valid but get %v", tc.raw, err)
+		} else {
+			ut.Assert(t, err != nil, "%s should be invalid", tc.raw)
+			ut.Equal(t, err.Error(), tc.errorMsg)
+		}
+	}
+
+	//rule is checked only if all the fields are selected
+	var l Listener
+	raw := map[string]interface{}{"protocol": "https"}
+	l.Protocol = "https"
+	ut.Assert(t, rf.ValidateFields(&l, raw, []string{"protocol"}) == nil, "")
+	ut.Assert(t, rf.ValidateFields(&l, raw, []string{"protocol", "tlsCert"}) != nil, "")
+	ut.Assert(t, rf.ValidateRules(&l, raw, []string{"protocol"}) != nil, "")
+	ut.Assert(t, rf.ValidateRules(&l, raw, []string{"token"}) == nil, "")
+
+	//marshalled resource has all the fields, zero value is treated as not set
+	l = Listener{Protocol: "http", Token: "abc"}
+	data, _ := json.Marshal(l)
+	raw = make(map[string]interface{})
+	json.Unmarshal(data, &raw)
+	ut.Assert(t, rf.ValidateRules(&l, raw, []string{"token"}) == nil, "")
+	l.Password = "123"
+	raw["password"] = "123"
+	ut.Assert(t, rf.ValidateRules(&l, raw, []string{"token"}) != nil, "")
+
+	invalidTypes := []interface{}{
+		struct {
+			End int `rest:"gtField=Start"`
+		}{},
+		struct {
+			Start string
+			End   int `rest:"gtField=Start"`
+		}{},
+		struct {
+			Start []int
+			End   []int `rest:"gtField=Start"`
+		}{},
+		struct {
+			Token string `rest:"excludes=Token"`
+		}{},
+		struct {
+			Protocol string
+			Cert     string `rest:"requiredIf=Protocol"`
+		}{},
+		struct {
+			Port int
+			Cert string `rest:"requiredIf=Port:https"`
+		}{},
+	}
+	for _, typ := range invalidTypes {
+		_, err := New(reflect.TypeOf(typ))
+		ut.Assert(t, err != nil, "rule of %v should be invalid", typ)
+	}
+}
+
+func TestCollectValidationErrors(t *testing.T) {
+	type Node struct {
+		Address string `json:"address" rest:"required=true,isIP"`
+	}
+
+	type Spec struct {
+		Replicas int `json:"replicas" rest:"min=1,max=10"`
+	}
+
+	type Cluster struct {
+		Name     string            `json:"name" rest:"required=true,minLen=2"`
+		Mode     string            `json:"mode" rest:"options=ha|single"`
+		Labels   map[string]string `json:"labels" rest:"maxLen=5"`
+		Nodes    []Node            `json:"nodes"`
+		Zones    map[string]Node   `json:"zones"`
+		Spec     Spec              `json:"spec" rest:"required=true"`
+		StartIP  string            `json:"startIP"`
+		EndIP    string            `json:"endIP" rest:"gtField=startIP"`
+		Password string            `json:"password" rest:"excludes=token"`
+		Token    string            `json:"token"`
+	}
+
+	rf, err := New(reflect.TypeOf(Cluster{}))
+	ut.Assert(t, err == nil, "")
+
+	data := `{"name":"c","mode":"multi","labels":{"b":"ok","a":"too long"},
+	"nodes":[{"address":"10.0.0.1"},{"address":"10.0.0"},{}],
+	"zones":{"z~1":{"address":"x"}},
+	"spec":{"replicas":0},
+	"startIP":"10.0.0.2","endIP":"10.0.0.1","password":"p","token":"t"}`
+	var c Cluster
+	raw := make(map[string]interface{})
+	ut.Assert(t, json.Unmarshal([]byte(data), &c) == nil, "")
+	ut.Assert(t, json.Unmarshal([]byte(data), &raw) == nil, "")
+	err = rf.Validate(&c, raw)
+	errs, ok := err.(ValidationErrors)
+	ut.Assert(t, ok, "validate should return validation errors but get %v", err)
+
+	expected := []struct {
+		path string
+		code goresterr.ErrorCode
+	}{
+		{"/name", goresterr.MinLengthExceeded},
+		{"/mode", goresterr.InvalidOption},
+		{"/labels/a", goresterr.MaxLengthExceeded},
+		{"/nodes/1/address", goresterr.InvalidIP},
+		{"/nodes/2/address", goresterr.MissingRequired},
+		{"/zones/z~01/address", goresterr.InvalidIP},
+		{"/spec/replicas", goresterr.MinLimitExceeded},
+		{"/endIP", goresterr.ComparisonFailed},
+		{"/password", goresterr.MutuallyExclusive},
+	}
+	ut.Equal(t, len(errs), len(expected))
+	for i, e := range expected {
+		ut.Equal(t, errs[i].Path, e.path)
+		ut.Equal(t, errs[i].ErrorCode, e.code)
+	}
+
+	apiErr := errs.APIError()
+	ut.Equal(t, apiErr.ErrorCode, goresterr.InvalidBodyContent)
+	ut.Equal(t, len(apiErr.Details), len(expected))
+	ut.Equal(t, errs[:1].APIError().ErrorCode, goresterr.MinLengthExceeded)
+}
+
+func TestExtendedKinds(t *testing.T) {
+	type Job struct {
+		Ratio    float64       `json:"ratio" rest:"min=0,max=1"`
+		Deadline time.Time     `json:"deadline" rest:"min=2020-01-01T00:00:00Z"`
+		Timeout  time.Duration `json:"timeout" rest:"default=30s,min=1s,max=1h"`
+		Interval string        `json:"interval" rest:"isDuration"`
+		Replicas *int          `json:"replicas" rest:"required=true,min=1"`
+		Comment  *string       `json:"comment" rest:"minLen=2"`
+		Enabled  *bool         `json:"enabled" rest:"required=true"`
+		Flags    []bool        `json:"flags" rest:"required=true"`
+		Start    *time.Time    `json:"start"`
+		End      *time.Time    `json:"end" rest:"gtField=start"`
+	}
+
+	rf, err := New(reflect.TypeOf(Job{}))
+	ut.Assert(t, err == nil, "build failed:%v", err)
+
+	validate := func(data string) ValidationErrors {
+		var job Job
+		raw := make(map[string]interface{})
+		ut.Assert(t, json.Unmarshal([]byte(data), &raw) == nil, "")
+		rf.FillDefaults(raw)
+		filled, _ := json.Marshal(raw)
+		ut.Assert(t, json.Unmarshal(filled, &job) == nil, "")
+		if err := rf.Validate(&job, raw); err != nil {
+			return err.(ValidationErrors)
+		}
+		return nil
+	}
+
+	errs := validate(`{"ratio":0.5,"deadline":"2021-01-01T00:00:00Z","replicas":1,"enabled":false,"flags":[true],
+	"start":"2021-01-01T00:00:00Z","end":"2021-01-02T00:00:00Z"}`)
+	ut.Assert(t, errs == nil, "job should be valid but get %v", errs)
+
+	errs = validate(`{"ratio":1.5,"deadline":"2019-01-01T00:00:00Z","timeout":1000,"interval":"10","replicas":null,"comment":"a",
+	"enabled":null,"flags":[],"start":"2021-01-02T00:00:00Z","end":"2021-01-01T00:00:00Z"}`)
+	expected := []struct {
+		path string
+		code goresterr.ErrorCode
+	}{
+		{"/ratio", goresterr.MaxLimitExceeded},
+		{"/deadline", goresterr.MinLimitExceeded},
+		{"/timeout", goresterr.MinLimitExceeded},
+		{"/interval", goresterr.InvalidDuration},
+		{"/replicas", goresterr.MissingRequired},
+		{"/comment", goresterr.MinLengthExceeded},
+		{"/enabled", goresterr.MissingRequired},
+		{"/flags", goresterr.MissingRequired},
+		{"/end", goresterr.ComparisonFailed},
+	}
+	ut.Equal(t, len(errs), len(expected))
+	for i, e := range expected {
+		ut.Equal(t, errs[i].Path, e.path)
+		ut.Equal(t, errs[i].ErrorCode, e.code)
+	}
+
+	raw := make(map[string]interface{})
+	rf.FillDefaults(raw)
+	ut.Equal(t, raw["timeout"], float64(30*time.Second))
+
+	//duration is integer nanoseconds in json, integer tag value is kept valid
+	type Probe struct {
+		Timeout time.Duration `json:"timeout" rest:"default=1000,min=1,max=100000"`
+	}
+	rf, err = New(reflect.TypeOf(Probe{}))
+	ut.Assert(t, err == nil, "build failed:%v", err)
+	raw = make(map[string]interface{})
+	rf.FillDefaults(raw)
+	ut.Equal(t, raw["timeout"], float64(1000))
+	ut.Assert(t, rf.Validate(&Probe{Timeout: 100000}, map[string]interface{}{"timeout": float64(100000)}) != nil, "")
+}
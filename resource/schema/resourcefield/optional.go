@@ -7,7 +7,11 @@ import (
 )
 
 const (
-	requiredTag = "required="
+	requiredTag  = "required="
+	statusTag    = "status"
+	readOnlyTag  = "readonly"
+	immutableTag = "immutable"
+	writeOnlyTag = "writeonly"
 )
 
 func fieldParseOptional(f Field, kind reflect.Kind, restTags []string) error {
@@ -21,8 +25,29 @@ func fieldParseOptional(f Field, kind reflect.Kind, restTags []string) error {
 			} else {
 				return fmt.Errorf("invalid require value %s", requiredVal)
 			}
+		} else if tag == statusTag {
+			f.SetStatus(true)
+		} else if tag == readOnlyTag {
+			f.SetReadOnly(true)
+		} else if tag == immutableTag {
+			f.SetImmutable(true)
+		} else if tag == writeOnlyTag {
+			f.SetWriteOnly(true)
 		}
 	}
 
+	if f.IsReadOnly() && f.IsRequired() {
+		return fmt.Errorf("readonly field %s cannot be required", f.JsonName())
+	}
+
 	return nil
 }
+
+func hasWriteOnlyTag(restTags []string) bool {
+	for _, tag := range restTags {
+		if tag == writeOnlyTag {
+			return true
+		}
+	}
+	return false
+}
@@ -2,10 +2,42 @@ package resourcefield
 
 import (
 	"reflect"
+	"sort"
+
+	"github.com/zdnscloud/cement/slice"
 )
 
+//errors returned by validation are ValidationErrors which collect
+//all the invalid fields
 type ResourceField interface {
 	Validate(interface{}, map[string]interface{}) error
+	//only validate the fields with specified json names, rules
+	//are checked only if all the fields involved are specified
+	ValidateFields(interface{}, map[string]interface{}, []string) error
+	//check the rules which involve any of the fields with specified
+	//json names, raw json data should be marshalled from the value which
+	//contains all the fields, so field with zero value is treated as not set
+	ValidateRules(interface{}, map[string]interface{}, []string) error
+	//json names of the fields with status tag
+	StatusFields() []string
+	//json names of the fields without status tag
+	SpecFields() []string
+	//json names of the fields with readonly tag
+	ReadOnlyFields() []string
+	//json names of the fields with immutable tag
+	ImmutableFields() []string
+	//json names of the fields with writeonly tag
+	WriteOnlyFields() []string
+	//whether any field including the nested ones is writeonly
+	HasWriteOnlyFields() bool
+	//remove writeonly fields from raw json data, fields of nested
+	//struct, slice and map are removed recursively
+	HideWriteOnlyFields(map[string]interface{})
+	//fill default values of the fields which aren't specified in raw
+	//json data, return true if any value is filled
+	FillDefaults(map[string]interface{}) bool
+	//only fill default values of the fields with specified json names
+	FillFieldDefaults(map[string]interface{}, []string) bool
 }
 
 func New(typ reflect.Type) (ResourceField, error) {
@@ -20,10 +52,10 @@ func New(typ reflect.Type) (ResourceField, error) {
 }
 
 type resourceField struct {
-	field Field
+	field *structField
 }
 
-func newResourceField(field Field) *resourceField {
+func newResourceField(field *structField) *resourceField {
 	return &resourceField{
 		field: field,
 	}
@@ -33,3 +65,87 @@ func newResourceField(field Field) *resourceField {
 func (f *resourceField) Validate(value interface{}, raw map[string]interface{}) error {
 	return f.field.Validate(value, raw)
 }
+
+func (f *resourceField) ValidateFields(value interface{}, raw map[string]interface{}, jsonNames []string) error {
+	selected := make(map[string]struct{})
+	for _, name := range jsonNames {
+		selected[name] = struct{}{}
+	}
+	var errs ValidationErrors
+	errs.add("", f.field.validateFields(value, raw, selected))
+	errs.add("", f.field.validateRules(value, raw, func(r rule) bool {
+		for _, name := range r.fieldNames() {
+			if _, ok := selected[name]; ok == false {
+				return false
+			}
+		}
+		return true
+	}, false))
+	return errs.err()
+}
+
+func (f *resourceField) ValidateRules(value interface{}, raw map[string]interface{}, jsonNames []string) error {
+	return f.field.validateRules(value, raw, func(r rule) bool {
+		for _, name := range r.fieldNames() {
+			if slice.SliceIndex(jsonNames, name) >= 0 {
+				return true
+			}
+		}
+		return false
+	}, true)
+}
+
+func (f *resourceField) StatusFields() []string {
+	return f.fieldNames(Field.IsStatus)
+}
+
+func (f *resourceField) SpecFields() []string {
+	return f.fieldNames(func(field Field) bool {
+		return field.IsStatus() == false
+	})
+}
+
+func (f *resourceField) ReadOnlyFields() []string {
+	return f.fieldNames(Field.IsReadOnly)
+}
+
+func (f *resourceField) ImmutableFields() []string {
+	return f.fieldNames(Field.IsImmutable)
+}
+
+func (f *resourceField) WriteOnlyFields() []string {
+	return f.fieldNames(Field.IsWriteOnly)
+}
+
+func (f *resourceField) HasWriteOnlyFields() bool {
+	return f.field.HasWriteOnly()
+}
+
+func (f *resourceField) HideWriteOnlyFields(raw map[string]interface{}) {
+	f.field.HideWriteOnly(raw)
+}
+
+func (f *resourceField) fieldNames(selected func(Field) bool) []string {
+	var names []string
+	for _, field := range f.field.fields {
+		if selected(field) {
+			names = append(names, field.JsonName())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (f *resourceField) FillDefaults(raw map[string]interface{}) bool {
+	return f.field.FillDefault(raw)
+}
+
+func (f *resourceField) FillFieldDefaults(raw map[string]interface{}, jsonNames []string) bool {
+	filled := false
+	for _, field := range f.field.fields {
+		if slice.SliceIndex(jsonNames, field.JsonName()) >= 0 && field.FillDefault(raw) {
+			filled = true
+		}
+	}
+	return filled
+}
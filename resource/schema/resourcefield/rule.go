@@ -0,0 +1,301 @@
+package resourcefield
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"reflect"
+	"strings"
+
+	goresterr "github.com/zdnscloud/gorest/error"
+	"github.com/zdnscloud/gorest/util"
+)
+
+//rules between the field and other fields in the same struct, eg:
+//gtField=startIP, excludes=a|b, requiredIf=protocol:https|wss
+const (
+	gtFieldTag        = "gtField="
+	gteFieldTag       = "gteField="
+	ltFieldTag        = "ltField="
+	lteFieldTag       = "lteField="
+	eqFieldTag        = "eqField="
+	neFieldTag        = "neField="
+	excludesTag       = "excludes="
+	requiredIfTag     = "requiredIf="
+	requiredUnlessTag = "requiredUnless="
+	//separator of field names and values
+	ruleElemSeparator = "|"
+	//separator of field name and values
+	ruleKeySeparator = ":"
+)
+
+type compareOp struct {
+	desc  string
+	match func(int) bool
+}
+
+var compareOps = map[string]compareOp{
+	gtFieldTag:  {"greater than", func(r int) bool { return r > 0 }},
+	gteFieldTag: {"greater than or equal to", func(r int) bool { return r >= 0 }},
+	ltFieldTag:  {"less than", func(r int) bool { return r < 0 }},
+	lteFieldTag: {"less than or equal to", func(r int) bool { return r <= 0 }},
+	eqFieldTag:  {"equal to", func(r int) bool { return r == 0 }},
+	neFieldTag:  {"not equal to", func(r int) bool { return r != 0 }},
+}
+
+//rules are checked after all the fields pass their own validation
+type rule interface {
+	//json names of the fields involved in the rule
+	fieldNames() []string
+	//value is the struct which the fields belong to, raw is its json data,
+	//marshalled means raw is marshalled from value instead of request
+	check(value reflect.Value, raw map[string]interface{}, marshalled bool) error
+}
+
+//rule tag declared on a field, it's resolved after all the fields
+//of the struct are built
+type ruleTag struct {
+	jsonName string
+	tag      string
+}
+
+func isRuleTag(tag string) bool {
+	if _, ok := compareOps[ruleTagPrefix(tag)]; ok {
+		return true
+	}
+	return strings.HasPrefix(tag, excludesTag) || strings.HasPrefix(tag, requiredIfTag) || strings.HasPrefix(tag, requiredUnlessTag)
+}
+
+func ruleTagPrefix(tag string) string {
+	if i := strings.Index(tag, "="); i >= 0 {
+		return tag[:i+1]
+	}
+	return tag
+}
+
+type fieldRef struct {
+	jsonName string
+	typ      reflect.Type
+	index    []int
+}
+
+func newFieldRef(typ reflect.Type, indexes map[string][]int, jsonName string) (fieldRef, error) {
+	index, ok := indexes[jsonName]
+	if ok == false {
+		return fieldRef{}, fmt.Errorf("field %s doesn't exist", jsonName)
+	}
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return fieldRef{
+		jsonName: jsonName,
+		typ:      typ.FieldByIndex(index).Type,
+		index:    index,
+	}, nil
+}
+
+//set to nil is same with not specified
+func (r fieldRef) isSpecified(raw map[string]interface{}) bool {
+	return raw[r.jsonName] != nil
+}
+
+//field specified in request is set even with zero value, but resource
+//marshalled from go struct has all the fields, so field with zero value
+//is treated as not set
+func (r fieldRef) isSet(v reflect.Value, raw map[string]interface{}, marshalled bool) bool {
+	if marshalled {
+		return r.isSpecified(raw) && r.value(v).IsZero() == false
+	}
+	return r.isSpecified(raw)
+}
+
+func (r fieldRef) value(v reflect.Value) reflect.Value {
+	return v.FieldByIndex(r.index)
+}
+
+func newRule(typ reflect.Type, indexes map[string][]int, rt ruleTag) (rule, error) {
+	self, err := newFieldRef(typ, indexes, rt.jsonName)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := ruleTagPrefix(rt.tag)
+	value := strings.TrimPrefix(rt.tag, prefix)
+	switch prefix {
+	case excludesTag:
+		r := &excludesRule{field: self}
+		for _, name := range strings.Split(value, ruleElemSeparator) {
+			other, err := newRuleFieldRef(typ, indexes, self, name)
+			if err != nil {
+				return nil, err
+			}
+			r.others = append(r.others, other)
+		}
+		return r, nil
+	case requiredIfTag, requiredUnlessTag:
+		kv := strings.SplitN(value, ruleKeySeparator, 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("rule %s of field %s has no value", rt.tag, self.jsonName)
+		}
+		other, err := newRuleFieldRef(typ, indexes, self, kv[0])
+		if err != nil {
+			return nil, err
+		}
+		r := &requiredIfRule{field: self, other: other, unless: prefix == requiredUnlessTag}
+		for _, s := range strings.Split(kv[1], ruleElemSeparator) {
+			v, err := parseLeafValue(derefType(other.typ), s)
+			if err != nil {
+				return nil, fmt.Errorf("rule %s of field %s is invalid:%s", rt.tag, self.jsonName, err.Error())
+			}
+			r.values = append(r.values, v)
+		}
+		return r, nil
+	default:
+		other, err := newRuleFieldRef(typ, indexes, self, value)
+		if err != nil {
+			return nil, err
+		}
+		if derefType(self.typ) != derefType(other.typ) {
+			return nil, fmt.Errorf("field %s and %s with different type can't be compared", self.jsonName, other.jsonName)
+		}
+		zero := reflect.New(derefType(self.typ)).Elem()
+		if _, err := util.CompareValue(zero, zero); err != nil {
+			return nil, fmt.Errorf("field %s can't be compared:%s", self.jsonName, err.Error())
+		}
+		return &compareRule{field: self, other: other, op: compareOps[prefix]}, nil
+	}
+}
+
+func newRuleFieldRef(typ reflect.Type, indexes map[string][]int, self fieldRef, jsonName string) (fieldRef, error) {
+	if jsonName == self.jsonName {
+		return fieldRef{}, fmt.Errorf("rule of field %s refers to itself", self.jsonName)
+	}
+	other, err := newFieldRef(typ, indexes, jsonName)
+	if err != nil {
+		return fieldRef{}, fmt.Errorf("rule of field %s is invalid:%s", self.jsonName, err.Error())
+	}
+	return other, nil
+}
+
+func derefType(typ reflect.Type) reflect.Type {
+	if typ.Kind() == reflect.Ptr {
+		return typ.Elem()
+	}
+	return typ
+}
+
+//nil pointer is returned as invalid value
+func derefValue(v reflect.Value) reflect.Value {
+	if v.Kind() == reflect.Ptr {
+		return v.Elem()
+	}
+	return v
+}
+
+//the fields are compared only when both of them are specified
+type compareRule struct {
+	field fieldRef
+	other fieldRef
+	op    compareOp
+}
+
+func (r *compareRule) fieldNames() []string {
+	return []string{r.field.jsonName, r.other.jsonName}
+}
+
+func (r *compareRule) check(value reflect.Value, raw map[string]interface{}, marshalled bool) error {
+	if r.field.isSpecified(raw) == false || r.other.isSpecified(raw) == false {
+		return nil
+	}
+
+	result, err := compareFieldValue(r.field.value(value), r.other.value(value))
+	if err != nil {
+		return err
+	}
+	if r.op.match(result) == false {
+		return goresterr.NewAPIError(goresterr.ComparisonFailed,
+			fmt.Sprintf("field %s should be %s field %s", r.field.jsonName, r.op.desc, r.other.jsonName))
+	}
+	return nil
+}
+
+//strings which are both ip addresses are compared as ip
+func compareFieldValue(a, b reflect.Value) (int, error) {
+	if va, vb := derefValue(a), derefValue(b); va.Kind() == reflect.String && vb.Kind() == reflect.String {
+		ipa, ipb := net.ParseIP(va.String()), net.ParseIP(vb.String())
+		if ipa != nil && ipb != nil {
+			return bytes.Compare(ipa.To16(), ipb.To16()), nil
+		}
+	}
+	return util.CompareValue(a, b)
+}
+
+//the field and other fields cannot be set at the same time
+type excludesRule struct {
+	field  fieldRef
+	others []fieldRef
+}
+
+func (r *excludesRule) fieldNames() []string {
+	names := []string{r.field.jsonName}
+	for _, other := range r.others {
+		names = append(names, other.jsonName)
+	}
+	return names
+}
+
+func (r *excludesRule) check(value reflect.Value, raw map[string]interface{}, marshalled bool) error {
+	if r.field.isSet(value, raw, marshalled) == false {
+		return nil
+	}
+	for _, other := range r.others {
+		if other.isSet(value, raw, marshalled) {
+			return goresterr.NewAPIError(goresterr.MutuallyExclusive,
+				fmt.Sprintf("field %s and field %s are mutually exclusive", r.field.jsonName, other.jsonName))
+		}
+	}
+	return nil
+}
+
+//the field should be set when value of other field is(or isn't for unless)
+//one of the values
+type requiredIfRule struct {
+	field  fieldRef
+	other  fieldRef
+	values []reflect.Value
+	unless bool
+}
+
+func (r *requiredIfRule) fieldNames() []string {
+	return []string{r.field.jsonName, r.other.jsonName}
+}
+
+func (r *requiredIfRule) check(value reflect.Value, raw map[string]interface{}, marshalled bool) error {
+	if r.field.isSet(value, raw, marshalled) || r.matchValues(r.other.value(value)) == r.unless {
+		return nil
+	}
+
+	var values []string
+	for _, v := range r.values {
+		values = append(values, fmt.Sprint(v.Interface()))
+	}
+	if r.unless {
+		return goresterr.NewAPIError(goresterr.MissingRequired,
+			fmt.Sprintf("field %s is required unless field %s is %s", r.field.jsonName, r.other.jsonName, strings.Join(values, " or ")))
+	} else {
+		return goresterr.NewAPIError(goresterr.MissingRequired,
+			fmt.Sprintf("field %s is required when field %s is %s", r.field.jsonName, r.other.jsonName, strings.Join(values, " or ")))
+	}
+}
+
+func (r *requiredIfRule) matchValues(v reflect.Value) bool {
+	if v = derefValue(v); v.IsValid() == false {
+		return false
+	}
+	for _, expect := range r.values {
+		if result, err := util.CompareValue(v, expect); err == nil && result == 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -1,19 +1,88 @@
 package validator
 
 import (
+	"fmt"
 	"reflect"
+	"strings"
+	"sync"
 
+	"github.com/zdnscloud/cement/slice"
+	goresterr "github.com/zdnscloud/gorest/error"
 	"github.com/zdnscloud/gorest/util"
 )
 
+//tags handled by resourcefield
+var reservedTags = []string{"required", "default", "description", "status", "readonly", "immutable", "writeonly",
+	"gtField", "gteField", "ltField", "lteField", "eqField", "neField", "excludes", "requiredIf", "requiredUnless"}
+
+var lock sync.RWMutex
+
+//builders registered by Register
+var customBuilders []CustomValidatorBuilder
+
 var builders []ValidatorBuilder = []ValidatorBuilder{
 	&domainNameValidatorBuilder{},
 	&stringLenRangeValidatorBuilder{},
 	&intRangeValidatorBuilder{},
 	&optionValidatorBuilder{},
+	&regexValidatorBuilder{},
+	newFormatValidatorBuilder(isIPTag, "IP address", goresterr.InvalidIP, isIP),
+	newFormatValidatorBuilder(isIPv4Tag, "IPv4 address", goresterr.InvalidIP, isIPv4),
+	newFormatValidatorBuilder(isIPv6Tag, "IPv6 address", goresterr.InvalidIP, isIPv6),
+	newFormatValidatorBuilder(isCIDRTag, "CIDR", goresterr.InvalidCIDR, isCIDR),
+	newFormatValidatorBuilder(isEmailTag, "email address", goresterr.InvalidEmail, isEmailAddress),
+	newFormatValidatorBuilder(isURLTag, "URL", goresterr.InvalidURL, isAbsoluteURL),
+	newFormatValidatorBuilder(isMACTag, "MAC address", goresterr.InvalidMAC, isMAC),
+	newFormatValidatorBuilder(isDurationTag, "duration", goresterr.InvalidDuration, isDuration),
+	newValueRangeValidatorBuilder(util.Float, parseFloat),
+	newValueRangeValidatorBuilder(util.Time, parseTime),
+	newValueRangeValidatorBuilder(util.Duration, parseDuration),
+}
+
+//custom builder should be registered before the resource kinds are imported,
+//its tag names shouldn't collide with any other builder, builtin builders
+//all declare their tag names
+func Register(builder CustomValidatorBuilder) error {
+	names := builder.TagNames()
+	if len(names) == 0 {
+		return fmt.Errorf("validator builder has no tag name")
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	for _, name := range names {
+		if name == "" || strings.Contains(name, "=") || strings.Contains(name, ",") {
+			return fmt.Errorf("invalid tag name %s", name)
+		}
+		if slice.SliceIndex(reservedTags, name) >= 0 {
+			return fmt.Errorf("tag name %s is reserved", name)
+		}
+		for _, b := range builders {
+			if tb, ok := b.(CustomValidatorBuilder); ok && slice.SliceIndex(tb.TagNames(), name) >= 0 {
+				return fmt.Errorf("tag name %s is used by other validator", name)
+			}
+		}
+	}
+	builders = append(builders, builder)
+	customBuilders = append(customBuilders, builder)
+	return nil
+}
+
+//whether the tag is handled by builder registered by Register
+func IsCustomTag(name string) bool {
+	lock.RLock()
+	defer lock.RUnlock()
+	for _, b := range customBuilders {
+		if slice.SliceIndex(b.TagNames(), name) >= 0 {
+			return true
+		}
+	}
+	return false
 }
 
 func Build(fieldType reflect.Type, tags []string) ([]Validator, error) {
+	lock.RLock()
+	defer lock.RUnlock()
 	var vs []Validator
 	kind := util.Inspect(fieldType)
 	for _, builder := range builders {
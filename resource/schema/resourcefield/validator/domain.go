@@ -6,6 +6,7 @@ import (
 	"regexp"
 	"strings"
 
+	goresterr "github.com/zdnscloud/gorest/error"
 	"github.com/zdnscloud/gorest/util"
 )
 
@@ -36,11 +37,11 @@ func (v *domainNameValidator) Validate(val interface{}) error {
 
 func validateDomain(s string) error {
 	if len(s) > DNS1123SubdomainMaxLength {
-		return fmt.Errorf("exceed max domain name len limitation(253)")
+		return goresterr.NewAPIError(goresterr.MaxLengthExceeded, "exceed max domain name len limitation(253)")
 	}
 
 	if !dns1123SubdomainRegexp.MatchString(s) {
-		return fmt.Errorf("subdomain must consist of lower case alphanumeric characters, '-' or '.', and must start and end with an alphanumeric character")
+		return goresterr.NewAPIError(goresterr.InvalidCharacters, "subdomain must consist of lower case alphanumeric characters, '-' or '.', and must start and end with an alphanumeric character")
 	}
 
 	return nil
@@ -55,6 +56,10 @@ func (b *domainNameValidatorBuilder) FromTags(tags []string) (Validator, error)
 	return nil, nil
 }
 
+func (b *domainNameValidatorBuilder) TagNames() []string {
+	return []string{"isDomain"}
+}
+
 func (b *domainNameValidatorBuilder) SupportKind(kind util.Kind) bool {
 	return kind == util.String ||
 		kind == util.StringSlice ||
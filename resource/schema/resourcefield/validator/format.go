@@ -0,0 +1,133 @@
+package validator
+
+import (
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	goresterr "github.com/zdnscloud/gorest/error"
+	"github.com/zdnscloud/gorest/util"
+)
+
+const (
+	isIPTag       = "isIP"
+	isIPv4Tag     = "isIPv4"
+	isIPv6Tag     = "isIPv6"
+	isCIDRTag     = "isCIDR"
+	isEmailTag    = "isEmail"
+	isURLTag      = "isURL"
+	isMACTag      = "isMAC"
+	isDurationTag = "isDuration"
+)
+
+//check string with specific format like ip, email
+type formatValidator struct {
+	format string
+	code   goresterr.ErrorCode
+	check  func(string) bool
+}
+
+//format validator is enabled by tag like isIP or isIP=true
+type formatValidatorBuilder struct {
+	tag       string
+	validator *formatValidator
+}
+
+func newFormatValidatorBuilder(tag, format string, code goresterr.ErrorCode, check func(string) bool) ValidatorBuilder {
+	return &formatValidatorBuilder{
+		tag: tag,
+		validator: &formatValidator{
+			format: format,
+			code:   code,
+			check:  check,
+		},
+	}
+}
+
+func (v *formatValidator) Validate(val interface{}) error {
+	value := reflect.ValueOf(val)
+	kind := util.Inspect(value.Type())
+	if kind != util.String {
+		return fmt.Errorf("%s check apply to non-string type: %v", v.format, kind)
+	}
+
+	if s := value.String(); v.check(s) == false {
+		return goresterr.NewAPIError(v.code, fmt.Sprintf("%s isn't valid %s", s, v.format))
+	}
+	return nil
+}
+
+func (b *formatValidatorBuilder) FromTags(tags []string) (Validator, error) {
+	for _, tag := range tags {
+		if tag == b.tag {
+			return b.validator, nil
+		}
+
+		if strings.HasPrefix(tag, b.tag+"=") {
+			enabled, err := strconv.ParseBool(strings.TrimPrefix(tag, b.tag+"="))
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s value in tag %s", b.tag, tag)
+			}
+			if enabled {
+				return b.validator, nil
+			}
+			return nil, nil
+		}
+	}
+	return nil, nil
+}
+
+func (b *formatValidatorBuilder) TagNames() []string {
+	return []string{b.tag}
+}
+
+func (b *formatValidatorBuilder) SupportKind(kind util.Kind) bool {
+	return kind == util.String ||
+		kind == util.StringSlice
+}
+
+func isIP(s string) bool {
+	return net.ParseIP(s) != nil
+}
+
+func isIPv4(s string) bool {
+	ip := net.ParseIP(s)
+	return ip != nil && ip.To4() != nil && strings.Contains(s, ":") == false
+}
+
+func isIPv6(s string) bool {
+	return net.ParseIP(s) != nil && strings.Contains(s, ":")
+}
+
+func isCIDR(s string) bool {
+	_, _, err := net.ParseCIDR(s)
+	return err == nil
+}
+
+//only bare address is valid, address with name like "ben <ben@zdns.cn>" is invalid
+func isEmailAddress(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	return err == nil && addr.Address == s
+}
+
+//url should be absolute with scheme and host
+func isAbsoluteURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && u.Scheme != "" && u.Host != ""
+}
+
+func isMAC(s string) bool {
+	_, err := net.ParseMAC(s)
+	return err == nil
+}
+
+//duration string like 1h30m
+func isDuration(s string) bool {
+	_, err := time.ParseDuration(s)
+	return err == nil
+}
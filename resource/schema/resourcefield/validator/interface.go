@@ -13,3 +13,12 @@ type ValidatorBuilder interface {
 	FromTags([]string) (Validator, error)
 	SupportKind(util.Kind) bool
 }
+
+//builder registered by Register should declare the tags it handles,
+//so they won't collide with the tags of other builders
+type CustomValidatorBuilder interface {
+	ValidatorBuilder
+	//name of the tags handled by the builder, name is the part
+	//before "=", eg: min for tag min=1
+	TagNames() []string
+}
@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"strings"
 
+	goresterr "github.com/zdnscloud/gorest/error"
 	"github.com/zdnscloud/gorest/util"
 )
 
@@ -40,11 +41,11 @@ func (v *intRangeValidator) Validate(val interface{}) error {
 
 func (v *intRangeValidator) validateValueRange(i int64) error {
 	if v.min != nil && i < *v.min {
-		return fmt.Errorf("exceed the range limit, (%v should >= %v)", i, *v.min)
+		return goresterr.NewAPIError(goresterr.MinLimitExceeded, fmt.Sprintf("exceed the range limit, (%v should >= %v)", i, *v.min))
 	}
 
 	if v.max != nil && i >= *v.max {
-		return fmt.Errorf("exceed the range limit, (%v should < %v)", i, *v.max)
+		return goresterr.NewAPIError(goresterr.MaxLimitExceeded, fmt.Sprintf("exceed the range limit, (%v should < %v)", i, *v.max))
 	}
 	return nil
 }
@@ -92,6 +93,10 @@ func (b *intRangeValidatorBuilder) FromTags(tags []string) (Validator, error) {
 	return newIntRangeValidator(min, max), nil
 }
 
+func (b *intRangeValidatorBuilder) TagNames() []string {
+	return []string{"min", "max"}
+}
+
 func (b *intRangeValidatorBuilder) SupportKind(kind util.Kind) bool {
 	return kind == util.Int ||
 		kind == util.Uint ||
@@ -0,0 +1,60 @@
+package validator
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	goresterr "github.com/zdnscloud/gorest/error"
+	"github.com/zdnscloud/gorest/util"
+)
+
+//rest tags are separated by comma, so comma in regex should be
+//escaped, eg: regex=^[a-z]{1\,3}$
+const regexTag = "regex="
+
+type regexValidator struct {
+	regex *regexp.Regexp
+}
+
+type regexValidatorBuilder struct{}
+
+func newRegexValidator(regex *regexp.Regexp) Validator {
+	return &regexValidator{regex: regex}
+}
+
+func (v *regexValidator) Validate(val interface{}) error {
+	value := reflect.ValueOf(val)
+	kind := util.Inspect(value.Type())
+	if kind != util.String {
+		return fmt.Errorf("regex apply to non-string type: %v", kind)
+	}
+
+	if s := value.String(); v.regex.MatchString(s) == false {
+		return goresterr.NewAPIError(goresterr.PatternMismatch, fmt.Sprintf("%s doesn't match pattern %s", s, v.regex.String()))
+	}
+	return nil
+}
+
+func (b *regexValidatorBuilder) FromTags(tags []string) (Validator, error) {
+	for _, tag := range tags {
+		if strings.HasPrefix(tag, regexTag) {
+			regex, err := regexp.Compile(strings.TrimPrefix(tag, regexTag))
+			if err != nil {
+				return nil, fmt.Errorf("invalid regex in tag %s:%s", tag, err.Error())
+			}
+			return newRegexValidator(regex), nil
+		}
+	}
+	return nil, nil
+}
+
+func (b *regexValidatorBuilder) TagNames() []string {
+	return []string{"regex"}
+}
+
+func (b *regexValidatorBuilder) SupportKind(kind util.Kind) bool {
+	return kind == util.String ||
+		kind == util.StringSlice
+}
@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"strings"
 
+	goresterr "github.com/zdnscloud/gorest/error"
 	"github.com/zdnscloud/gorest/util"
 )
 
@@ -38,10 +39,10 @@ func (v *stringLenRangeValidator) Validate(val interface{}) error {
 func (v *stringLenRangeValidator) validateStringLen(s string) error {
 	l := int64(len(s))
 	if v.minLen != nil && l < *v.minLen {
-		return fmt.Errorf("exceed the range limit, (string len %v should >= %v)", l, *v.minLen)
+		return goresterr.NewAPIError(goresterr.MinLengthExceeded, fmt.Sprintf("exceed the range limit, (string len %v should >= %v)", l, *v.minLen))
 	}
 	if v.maxLen != nil && l >= *v.maxLen {
-		return fmt.Errorf("exceed the range limit, (string len %v should < %v)", l, *v.maxLen)
+		return goresterr.NewAPIError(goresterr.MaxLengthExceeded, fmt.Sprintf("exceed the range limit, (string len %v should < %v)", l, *v.maxLen))
 	}
 	return nil
 }
@@ -89,6 +90,10 @@ func (b *stringLenRangeValidatorBuilder) FromTags(tags []string) (Validator, err
 	return newStringLenRangeValidator(minLen, maxLen), nil
 }
 
+func (b *stringLenRangeValidatorBuilder) TagNames() []string {
+	return []string{"minLen", "maxLen"}
+}
+
 func (b *stringLenRangeValidatorBuilder) SupportKind(kind util.Kind) bool {
 	return kind == util.String ||
 		kind == util.StringSlice ||
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/zdnscloud/cement/slice"
+	goresterr "github.com/zdnscloud/gorest/error"
 	"github.com/zdnscloud/gorest/util"
 )
 
@@ -32,7 +33,7 @@ func (v *optionValidator) Validate(val interface{}) error {
 	}
 	sv := value.String()
 	if slice.SliceIndex(v.options, sv) == -1 {
-		return fmt.Errorf("%s isn't included in options %v", sv, v.options)
+		return goresterr.NewAPIError(goresterr.InvalidOption, fmt.Sprintf("%s isn't included in options %v", sv, v.options))
 	}
 	return nil
 }
@@ -47,6 +48,10 @@ func (b *optionValidatorBuilder) FromTags(tags []string) (Validator, error) {
 	return nil, nil
 }
 
+func (b *optionValidatorBuilder) TagNames() []string {
+	return []string{"options"}
+}
+
 func (b *optionValidatorBuilder) SupportKind(kind util.Kind) bool {
 	return kind == util.String ||
 		kind == util.StringSlice ||
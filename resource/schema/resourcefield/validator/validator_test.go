@@ -1,11 +1,15 @@
 package validator
 
 import (
+	"fmt"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	ut "github.com/zdnscloud/cement/unittest"
+	goresterr "github.com/zdnscloud/gorest/error"
+	"github.com/zdnscloud/gorest/util"
 )
 
 func TestBuildValidator(t *testing.T) {
@@ -132,6 +136,55 @@ func TestDomainValidator(t *testing.T) {
 	testValidator(t, []string{"xxxx"}, []string{"isDomain=true"}, cases)
 }
 
+func TestValueRangeValidator(t *testing.T) {
+	type Ratio float32
+	testValidator(t, Ratio(0), []string{"min=0.5", "max=1"}, []testCase{
+		{Ratio(0.5), true},
+		{Ratio(0.99), true},
+		{Ratio(0.1), false},
+		{Ratio(1), false},
+	})
+
+	testValidator(t, time.Time{}, []string{"min=2020-01-01T00:00:00Z"}, []testCase{
+		{time.Date(2020, 1, 1, 8, 0, 0, 0, time.FixedZone("CST", 8*3600)), true},
+		{time.Date(2019, 12, 31, 0, 0, 0, 0, time.UTC), false},
+	})
+
+	testValidator(t, time.Duration(0), []string{"min=1s", "max=1h"}, []testCase{
+		{time.Second, true},
+		{30 * time.Minute, true},
+		{time.Millisecond, false},
+		{time.Hour, false},
+	})
+
+	//limit of duration could be integer nanoseconds
+	testValidator(t, time.Duration(0), []string{"min=1", "max=100"}, []testCase{
+		{time.Duration(1), true},
+		{time.Duration(99), true},
+		{time.Duration(0), false},
+		{time.Duration(100), false},
+	})
+
+	testValidator(t, "", []string{"isDuration"}, []testCase{
+		{"1h30m", true},
+		{"90", false},
+	})
+
+	invalidTags := []struct {
+		value interface{}
+		tags  []string
+	}{
+		{float64(0), []string{"min=a"}},
+		{float64(0), []string{"min=2", "max=1"}},
+		{time.Time{}, []string{"max=2020-01-01"}},
+		{time.Duration(0), []string{"min=10x"}},
+	}
+	for _, tc := range invalidTags {
+		_, err := Build(reflect.TypeOf(tc.value), tc.tags)
+		ut.Assert(t, err != nil, "tags %v should be invalid for %v", tc.tags, reflect.TypeOf(tc.value))
+	}
+}
+
 func testValidator(t *testing.T, fieldValue interface{}, tags []string, cases []testCase) {
 	validators, err := Build(reflect.TypeOf(fieldValue), tags)
 	ut.Assert(t, err == nil && len(validators) == 1, "")
@@ -145,3 +198,119 @@ func testValidator(t *testing.T, fieldValue interface{}, tags []string, cases []
 		}
 	}
 }
+
+func TestFormatValidator(t *testing.T) {
+	cases := []struct {
+		tag     string
+		valid   []string
+		invalid []string
+		code    goresterr.ErrorCode
+	}{
+		{"regex=^[a-z]+[0-9]*$", []string{"abc", "ab12"}, []string{"12ab", "AB"}, goresterr.PatternMismatch},
+		{"isIP", []string{"10.0.0.1", "2001:db8::1"}, []string{"10.0.0.256", "abc"}, goresterr.InvalidIP},
+		{"isIPv4=true", []string{"10.0.0.1"}, []string{"2001:db8::1", "::ffff:10.0.0.1"}, goresterr.InvalidIP},
+		{"isIPv6", []string{"2001:db8::1", "::ffff:10.0.0.1"}, []string{"10.0.0.1"}, goresterr.InvalidIP},
+		{"isCIDR", []string{"10.0.0.0/8", "2001:db8::/32"}, []string{"10.0.0.1", "10.0.0.0/33"}, goresterr.InvalidCIDR},
+		{"isEmail", []string{"ben@zdns.cn"}, []string{"ben", "ben <ben@zdns.cn>"}, goresterr.InvalidEmail},
+		{"isURL", []string{"http://zdns.cn/a?b=c", "ws://10.0.0.1:80"}, []string{"zdns.cn", "/a/b", "http://"}, goresterr.InvalidURL},
+		{"isMAC", []string{"00:1b:63:84:45:e6", "00-1B-63-84-45-E6"}, []string{"00:1b:63:84:45", "zz:1b:63:84:45:e6"}, goresterr.InvalidMAC},
+	}
+
+	for _, tc := range cases {
+		for _, typ := range []reflect.Type{reflect.TypeOf(""), reflect.TypeOf([]string{})} {
+			validators, err := Build(typ, []string{tc.tag})
+			ut.Assert(t, err == nil && len(validators) == 1, "build %s failed:%v", tc.tag, err)
+			for _, s := range tc.valid {
+				ut.Assert(t, validators[0].Validate(s) == nil, "%s should be valid for %s", s, tc.tag)
+			}
+			for _, s := range tc.invalid {
+				err := validators[0].Validate(s)
+				apiErr, ok := err.(*goresterr.APIError)
+				ut.Assert(t, ok && apiErr.ErrorCode == tc.code, "%s should be invalid for %s:%v", s, tc.tag, err)
+			}
+		}
+	}
+
+	validators, err := Build(reflect.TypeOf(""), []string{"isIP=false"})
+	ut.Assert(t, err == nil && len(validators) == 0, "")
+
+	invalidTags := []struct {
+		typ reflect.Type
+		tag string
+	}{
+		{reflect.TypeOf(""), "regex=[a-z"},
+		{reflect.TypeOf(""), "isIP=yes"},
+	}
+	for _, tc := range invalidTags {
+		_, err := Build(tc.typ, []string{tc.tag})
+		ut.Assert(t, err != nil, "tag %s should be invalid", tc.tag)
+	}
+
+	validators, _ = Build(reflect.TypeOf(0), []string{"isIP"})
+	ut.Equal(t, len(validators), 0)
+}
+
+type zoneValidator struct{}
+
+func (v *zoneValidator) Validate(val interface{}) error {
+	if strings.HasSuffix(reflect.ValueOf(val).String(), ".") == false {
+		return fmt.Errorf("zone should end with dot")
+	}
+	return nil
+}
+
+type zoneValidatorBuilder struct {
+	names []string
+}
+
+func (b *zoneValidatorBuilder) FromTags(tags []string) (Validator, error) {
+	for _, tag := range tags {
+		if tag == "isZone" {
+			return &zoneValidator{}, nil
+		}
+	}
+	return nil, nil
+}
+
+func (b *zoneValidatorBuilder) SupportKind(kind util.Kind) bool {
+	return kind == util.String
+}
+
+func (b *zoneValidatorBuilder) TagNames() []string {
+	return b.names
+}
+
+//remove the builder registered by Register, so the test could be run repeatedly
+func unregister(builder CustomValidatorBuilder) {
+	lock.Lock()
+	defer lock.Unlock()
+	for i, b := range builders {
+		if b == builder {
+			builders = append(builders[:i], builders[i+1:]...)
+			break
+		}
+	}
+	for i, b := range customBuilders {
+		if b == builder {
+			customBuilders = append(customBuilders[:i], customBuilders[i+1:]...)
+			break
+		}
+	}
+}
+
+func TestRegister(t *testing.T) {
+	zone := &zoneValidatorBuilder{names: []string{"isZone"}}
+	ut.Assert(t, Register(zone) == nil, "")
+	defer unregister(zone)
+	ut.Assert(t, IsCustomTag("isZone"), "")
+	ut.Assert(t, IsCustomTag("isIP") == false, "")
+
+	validators, err := Build(reflect.TypeOf(""), []string{"isZone"})
+	ut.Assert(t, err == nil && len(validators) == 1, "")
+	ut.Assert(t, validators[0].Validate("zdns.cn.") == nil, "")
+	ut.Assert(t, validators[0].Validate("zdns.cn") != nil, "")
+
+	for _, names := range [][]string{{"isZone"}, {"min"}, {"isIP"}, {"required"}, {"default"}, {}, {""}, {"a=b"}} {
+		ut.Assert(t, Register(&zoneValidatorBuilder{names: names}) != nil, "register %v should fail", names)
+	}
+}
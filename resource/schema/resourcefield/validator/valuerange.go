@@ -0,0 +1,133 @@
+package validator
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	goresterr "github.com/zdnscloud/gorest/error"
+	"github.com/zdnscloud/gorest/util"
+)
+
+//range of float, time and duration share the tags with int range,
+//time is in RFC3339 format, duration is like 1h30m or integer nanoseconds,
+//eg: min=1s,max=1h
+type valueRangeValidator struct {
+	min *reflect.Value
+	max *reflect.Value
+}
+
+type valueRangeValidatorBuilder struct {
+	kind  util.Kind
+	parse func(string) (reflect.Value, error)
+}
+
+func newValueRangeValidatorBuilder(kind util.Kind, parse func(string) (reflect.Value, error)) ValidatorBuilder {
+	return &valueRangeValidatorBuilder{
+		kind:  kind,
+		parse: parse,
+	}
+}
+
+func (v *valueRangeValidator) Validate(val interface{}) error {
+	value := reflect.ValueOf(val)
+	if v.min != nil {
+		if result, err := compareWithLimit(value, *v.min); err != nil {
+			return err
+		} else if result < 0 {
+			return goresterr.NewAPIError(goresterr.MinLimitExceeded, fmt.Sprintf("exceed the range limit, (%v should >= %v)", val, *v.min))
+		}
+	}
+
+	if v.max != nil {
+		if result, err := compareWithLimit(value, *v.max); err != nil {
+			return err
+		} else if result >= 0 {
+			return goresterr.NewAPIError(goresterr.MaxLimitExceeded, fmt.Sprintf("exceed the range limit, (%v should < %v)", val, *v.max))
+		}
+	}
+	return nil
+}
+
+//limit is converted to the type of value which may be a named type
+func compareWithLimit(value, limit reflect.Value) (int, error) {
+	if limit.Type().ConvertibleTo(value.Type()) == false {
+		return 0, fmt.Errorf("value range apply to invalid type:%v", value.Type())
+	}
+	return util.CompareValue(value, limit.Convert(value.Type()))
+}
+
+func (b *valueRangeValidatorBuilder) FromTags(tags []string) (Validator, error) {
+	var minStr, maxStr string
+	for _, tag := range tags {
+		if strings.HasPrefix(tag, minPrefix) {
+			if minStr != "" {
+				return nil, fmt.Errorf("%s range has duplicate min tag", b.kind)
+			}
+			minStr = strings.TrimPrefix(tag, minPrefix)
+		} else if strings.HasPrefix(tag, maxPrefix) {
+			if maxStr != "" {
+				return nil, fmt.Errorf("%s range has duplicate max tag", b.kind)
+			}
+			maxStr = strings.TrimPrefix(tag, maxPrefix)
+		}
+	}
+
+	if minStr == "" && maxStr == "" {
+		return nil, nil
+	}
+
+	var min, max *reflect.Value
+	if minStr != "" {
+		min_, err := b.parse(minStr)
+		if err != nil {
+			return nil, fmt.Errorf("min value isn't valid %s:%s", b.kind, err.Error())
+		}
+		min = &min_
+	}
+
+	if maxStr != "" {
+		max_, err := b.parse(maxStr)
+		if err != nil {
+			return nil, fmt.Errorf("max value isn't valid %s:%s", b.kind, err.Error())
+		}
+		max = &max_
+	}
+
+	if min != nil && max != nil {
+		if result, err := util.CompareValue(*min, *max); err != nil || result >= 0 {
+			return nil, fmt.Errorf("min value should smaller than max")
+		}
+	}
+	return &valueRangeValidator{min: min, max: max}, nil
+}
+
+func (b *valueRangeValidatorBuilder) TagNames() []string {
+	return []string{"min", "max"}
+}
+
+func (b *valueRangeValidatorBuilder) SupportKind(kind util.Kind) bool {
+	return kind == b.kind
+}
+
+func parseFloat(s string) (reflect.Value, error) {
+	f, err := strconv.ParseFloat(s, 64)
+	return reflect.ValueOf(f), err
+}
+
+func parseTime(s string) (reflect.Value, error) {
+	t, err := time.Parse(time.RFC3339, s)
+	return reflect.ValueOf(t), err
+}
+
+//duration is encoded as integer nanoseconds in json, so integer limit
+//is accepted as well
+func parseDuration(s string) (reflect.Value, error) {
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return reflect.ValueOf(time.Duration(i)), nil
+	}
+	d, err := time.ParseDuration(s)
+	return reflect.ValueOf(d), err
+}
@@ -4,15 +4,20 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"path"
 	"reflect"
 
+	slice "github.com/zdnscloud/cement/slice"
 	goresterr "github.com/zdnscloud/gorest/error"
 	"github.com/zdnscloud/gorest/resource"
 	"github.com/zdnscloud/gorest/resource/schema/resourcedoc"
 	"github.com/zdnscloud/gorest/resource/schema/resourcefield"
 )
 
+//path segment of status subresource
+const statusSegment = "status"
+
 type Schema struct {
 	version          *resource.APIVersion
 	fields           resourcefield.ResourceField
@@ -22,6 +27,10 @@ type Schema struct {
 	resourceName     string
 	resourceKindName string
 	children         []*Schema
+	tasks            *resource.TaskManager
+	//action name to fields of action input
+	actionFields           map[string]resourcefield.ResourceField
+	collectionActionFields map[string]resourcefield.ResourceField
 }
 
 func NewSchema(version *resource.APIVersion, kind resource.ResourceKind, handler resource.Handler) (*Schema, error) {
@@ -38,14 +47,96 @@ func NewSchema(version *resource.APIVersion, kind resource.ResourceKind, handler
 		return nil, err
 	}
 
-	return &Schema{
-		version:          version,
-		fields:           fields,
-		handler:          handler,
-		resourceKind:     kind,
-		resourceName:     resource.DefaultResourceName(kind),
-		resourceKindName: resource.DefaultKindName(kind),
-	}, nil
+	actionFields, err := buildActionFields(kind.GetActions())
+	if err != nil {
+		return nil, err
+	}
+
+	collectionActionFields, err := buildActionFields(kind.GetCollectionActions())
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Schema{
+		version:                version,
+		fields:                 fields,
+		handler:                handler,
+		resourceKind:           kind,
+		resourceName:           resource.DefaultResourceName(kind),
+		resourceKindName:       resource.DefaultKindName(kind),
+		actionFields:           actionFields,
+		collectionActionFields: collectionActionFields,
+	}
+	for _, action := range kind.GetCollectionActions() {
+		if action.Async {
+			return nil, fmt.Errorf("collection action %s of %s cannot be async", action.Name, s.resourceKindName)
+		}
+	}
+	if kind.SupportStatus() && (fields == nil || len(fields.StatusFields()) == 0) {
+		return nil, fmt.Errorf("resource kind %s supports status but has no status field", s.resourceKindName)
+	}
+	//immutable fields are checked against the resource returned by get handler
+	if fields != nil && len(fields.ImmutableFields()) > 0 && handler.GetGetHandler() == nil &&
+		(handler.GetUpdateHandler() != nil || handler.GetPatchHandler() != nil) {
+		return nil, fmt.Errorf("resource kind %s has immutable field but no get handler", s.resourceKindName)
+	}
+	if hasAsyncAction(kind) {
+		if err := s.addTaskSchema(); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+//only struct input could be validated
+func buildActionFields(actions []resource.Action) (map[string]resourcefield.ResourceField, error) {
+	actionFields := make(map[string]resourcefield.ResourceField)
+	for _, action := range actions {
+		if action.Input == nil {
+			continue
+		}
+
+		typ := reflect.TypeOf(action.Input)
+		if typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+		if typ.Kind() != reflect.Struct {
+			continue
+		}
+
+		fields, err := resourcefield.New(typ)
+		if err != nil {
+			return nil, fmt.Errorf("input of action %s is invalid:%s", action.Name, err.Error())
+		}
+		if fields != nil {
+			actionFields[action.Name] = fields
+		}
+	}
+	return actionFields, nil
+}
+
+func hasAsyncAction(kind resource.ResourceKind) bool {
+	for _, action := range kind.GetActions() {
+		if action.Async {
+			return true
+		}
+	}
+	return false
+}
+
+//tasks of async actions are served as child resource
+func (s *Schema) addTaskSchema() error {
+	tasks := resource.NewTaskManager()
+	child, err := NewSchema(s.version, resource.Task{}, resource.NewTaskHandler(tasks))
+	if err != nil {
+		return err
+	}
+	s.tasks = tasks
+	return s.AddChild(child)
+}
+
+func (s *Schema) GetTaskManager() *resource.TaskManager {
+	return s.tasks
 }
 
 func (s *Schema) Equal(other *Schema) bool {
@@ -64,7 +155,7 @@ func (s *Schema) GetChildren() []*Schema {
 	return s.children
 }
 
-func (s *Schema) CreateResourceFromPathSegments(parent resource.Resource, segments []string, method, action string, body []byte) (resource.Resource, *goresterr.APIError) {
+func (s *Schema) CreateResourceFromPathSegments(parent resource.Resource, segments []string, method, action, contentType string, body []byte) (resource.Resource, *goresterr.APIError) {
 	segmentCount := len(segments)
 	if segmentCount == 0 {
 		return parent, nil
@@ -74,9 +165,13 @@ func (s *Schema) CreateResourceFromPathSegments(parent resource.Resource, segmen
 		return nil, nil
 	}
 
-	r := s.resourceKind.CreateDefaultResource()
+	//fields not specified in patch keep unchanged, so default value shouldn't be used
+	var r resource.Resource
+	if method != http.MethodPatch {
+		r = s.resourceKind.CreateDefaultResource()
+	}
 	if r == nil {
-		r = reflect.New(reflect.TypeOf(s.resourceKind)).Interface().(resource.Resource)
+		r = s.newResource()
 	}
 
 	r.SetSchema(s)
@@ -85,74 +180,396 @@ func (s *Schema) CreateResourceFromPathSegments(parent resource.Resource, segmen
 	}
 
 	r.SetType(resource.DefaultKindName(s.resourceKind))
-	if segmentCount > 1 {
+	//singleton has no id segment
+	consumed := 2
+	if s.resourceKind.IsSingleton() {
+		consumed = 1
+	} else if segmentCount > 1 {
 		r.SetID(segments[1])
 	}
-	if segmentCount <= 2 {
-		if err := s.validateAndFillResource(r, method, action, body); err != nil {
+	if segmentCount <= consumed {
+		if s.resourceKind.IsSingleton() && resource.IsSingletonMethod(resource.HttpMethod(method)) == false {
+			return nil, goresterr.NewAPIError(goresterr.MethodNotAllowed,
+				fmt.Sprintf("singleton %s doesn't support %s", s.resourceName, method))
+		}
+		if err := s.validateAndFillResource(r, method, action, contentType, body); err != nil {
 			return nil, err
 		} else {
 			return r, nil
 		}
 	}
 
+	if segments[consumed] == statusSegment && segmentCount == consumed+1 && s.resourceKind.SupportStatus() {
+		if method != http.MethodPut {
+			return nil, goresterr.NewAPIError(goresterr.MethodNotAllowed,
+				fmt.Sprintf("status of %s doesn't support %s", s.resourceName, method))
+		}
+		r.SetStatusUpdate(true)
+		if err := s.fillResource(r, method, body); err != nil {
+			return nil, err
+		}
+		return r, nil
+	}
+
 	for _, child := range s.children {
-		if r, err := child.CreateResourceFromPathSegments(r, segments[2:], method, action, body); err != nil {
+		if r, err := child.CreateResourceFromPathSegments(r, segments[consumed:], method, action, contentType, body); err != nil {
 			return nil, err
 		} else if r != nil {
 			return r, nil
 		}
 	}
 	return nil, goresterr.NewAPIError(goresterr.NotFound,
-		fmt.Sprintf("%s is not a child of %s", segments[2], s.resourceName))
+		fmt.Sprintf("%s is not a child of %s", segments[consumed], s.resourceName))
 }
 
-func (s *Schema) validateAndFillResource(r resource.Resource, method, action string, body []byte) *goresterr.APIError {
+func (s *Schema) newResource() resource.Resource {
+	return reflect.New(reflect.TypeOf(s.resourceKind)).Interface().(resource.Resource)
+}
+
+func (s *Schema) validateAndFillResource(r resource.Resource, method, action, contentType string, body []byte) *goresterr.APIError {
 	if method == http.MethodPost && action != "" {
-		if action_, err := s.parseAction(action, body); err != nil {
+		if action_, err := s.parseAction(action, body, r.GetID() == ""); err != nil {
 			return err
 		} else {
 			r.SetAction(action_)
 		}
 	} else if method == http.MethodPost || method == http.MethodPut {
-		if body != nil {
-			json.Unmarshal(body, r)
-		}
-		if s.fields != nil {
-			objMap := make(map[string]interface{})
-			if body != nil {
-				if err := json.Unmarshal(body, &objMap); err != nil {
-					return goresterr.NewAPIError(goresterr.InvalidBodyContent, fmt.Sprintf("request body isn't a string map:%s", err.Error()))
+		return s.fillResource(r, method, body)
+	} else if method == http.MethodPatch {
+		patch, err := resource.NewPatch(contentType, body)
+		if err != nil {
+			return err
+		}
+		if dropped := s.droppedPatchFields(); len(dropped) > 0 {
+			if err := patch.RemoveFields(dropped); err != nil {
+				return goresterr.NewAPIError(goresterr.InvalidBodyContent, err.Error())
+			}
+		}
+		r.SetPatch(patch)
+		//json patch may refer to existing value, it can only be applied
+		//when the resource to patch is fetched
+		if patch.Type == resource.MergePatchType {
+			return s.fillPatchedResource(r, patch.Data, false)
+		}
+	}
+	return nil
+}
+
+//fields not accepted by the request are dropped and not validated,
+//readonly fields are never accepted, for kind supports status, update
+//of the resource doesn't accept fields with status tag, while update
+//of the status only accepts fields with status tag, default values are
+//only filled for accepted spec fields, never for status update
+func (s *Schema) fillResource(r resource.Resource, method string, body []byte) *goresterr.APIError {
+	objMap := make(map[string]interface{})
+	if body != nil {
+		if err := json.Unmarshal(body, &objMap); err != nil {
+			return goresterr.NewAPIError(goresterr.InvalidBodyContent, fmt.Sprintf("request body isn't a string map:%s", err.Error()))
+		}
+	}
+
+	if s.fields == nil {
+		return unmarshalResource(r, body)
+	}
+
+	statusFields := s.fields.StatusFields()
+	readOnlyFields := s.fields.ReadOnlyFields()
+	ignoreStatus := method == http.MethodPut && s.resourceKind.SupportStatus()
+	accepted := make(map[string]interface{})
+	for name, value := range objMap {
+		isStatus := slice.SliceIndex(statusFields, name) >= 0
+		if r.IsStatusUpdate() {
+			if isStatus {
+				accepted[name] = value
+			}
+		} else if slice.SliceIndex(readOnlyFields, name) == -1 && (isStatus == false || ignoreStatus == false) {
+			accepted[name] = value
+		}
+	}
+
+	filled := false
+	if r.IsStatusUpdate() == false {
+		var defaultFields []string
+		for _, name := range s.fields.SpecFields() {
+			if slice.SliceIndex(readOnlyFields, name) == -1 {
+				defaultFields = append(defaultFields, name)
+			}
+		}
+		filled = s.fields.FillFieldDefaults(accepted, defaultFields)
+	}
+
+	//body is used directly if it's accepted as a whole, so numbers
+	//don't lose precision through float64
+	data := body
+	if filled || len(accepted) != len(objMap) {
+		data, _ = json.Marshal(accepted)
+	}
+	if err := unmarshalResource(r, data); err != nil {
+		return err
+	}
+
+	var err error
+	if r.IsStatusUpdate() {
+		err = s.fields.ValidateFields(r, accepted, statusFields)
+	} else if ignoreStatus {
+		err = s.fields.ValidateFields(r, accepted, s.fields.SpecFields())
+	} else {
+		err = s.fields.Validate(r, accepted)
+	}
+	if err != nil {
+		return validationError(err)
+	}
+	return nil
+}
+
+func unmarshalResource(r resource.Resource, data []byte) *goresterr.APIError {
+	if len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, r); err != nil {
+		return goresterr.NewAPIError(goresterr.InvalidBodyContent, fmt.Sprintf("request body is invalid:%s", err.Error()))
+	}
+	return nil
+}
+
+//validation collects the errors of all the invalid fields
+func validationError(err error) *goresterr.APIError {
+	switch e := err.(type) {
+	case resourcefield.ValidationErrors:
+		return e.APIError()
+	case *goresterr.APIError:
+		return e
+	}
+	return goresterr.NewAPIError(goresterr.InvalidBodyContent, err.Error())
+}
+
+//readonly fields can't be modified by patch, nor can status fields
+//which are only updated through status subresource
+func (s *Schema) droppedPatchFields() []string {
+	if s.fields == nil {
+		return nil
+	}
+	return append(s.fields.ReadOnlyFields(), s.GetStatusFields()...)
+}
+
+func (s *Schema) GetStatusFields() []string {
+	if s.fields == nil || s.resourceKind.SupportStatus() == false {
+		return nil
+	}
+	return s.fields.StatusFields()
+}
+
+func (s *Schema) KeepStatusFields(r resource.Resource, current resource.Resource) *goresterr.APIError {
+	fields := s.GetStatusFields()
+	if len(fields) == 0 {
+		return nil
+	}
+
+	currentObj, err := toJsonMap(current)
+	if err != nil {
+		return goresterr.NewAPIError(goresterr.ServerError, fmt.Sprintf("marshal resource failed:%s", err.Error()))
+	}
+	status := make(map[string]interface{})
+	for _, name := range fields {
+		if value, ok := currentObj[name]; ok {
+			status[name] = value
+		}
+	}
+	data, _ := json.Marshal(status)
+	if err := json.Unmarshal(data, r); err != nil {
+		return goresterr.NewAPIError(goresterr.ServerError, fmt.Sprintf("keep status of resource failed:%s", err.Error()))
+	}
+	return nil
+}
+
+func (s *Schema) GetImmutableFields() []string {
+	if s.fields == nil {
+		return nil
+	}
+	return s.fields.ImmutableFields()
+}
+
+func (s *Schema) CheckImmutableFields(r resource.Resource, current resource.Resource) *goresterr.APIError {
+	fields := s.GetImmutableFields()
+	if len(fields) == 0 {
+		return nil
+	}
+
+	newObj, err := toJsonMap(r)
+	if err != nil {
+		return goresterr.NewAPIError(goresterr.ServerError, fmt.Sprintf("marshal resource failed:%s", err.Error()))
+	}
+	currentObj, err := toJsonMap(current)
+	if err != nil {
+		return goresterr.NewAPIError(goresterr.ServerError, fmt.Sprintf("marshal resource failed:%s", err.Error()))
+	}
+	for _, name := range fields {
+		if reflect.DeepEqual(newObj[name], currentObj[name]) == false {
+			return goresterr.NewAPIError(goresterr.InvalidBodyContent, fmt.Sprintf("field %s is immutable", name))
+		}
+	}
+	return nil
+}
+
+func toJsonMap(r resource.Resource) (map[string]interface{}, error) {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	obj := make(map[string]interface{})
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+func (s *Schema) ApplyPatch(r resource.Resource, current resource.Resource) (resource.Resource, *goresterr.APIError) {
+	patch := r.GetPatch()
+	if patch == nil {
+		return nil, goresterr.NewAPIError(goresterr.ServerError, "resource has no patch")
+	}
+
+	doc := []byte("{}")
+	if current != nil {
+		var err error
+		if doc, err = json.Marshal(current); err != nil {
+			return nil, goresterr.NewAPIError(goresterr.ServerError, fmt.Sprintf("marshal resource failed:%s", err.Error()))
+		}
+	}
+
+	data, err := patch.Apply(doc)
+	if err != nil {
+		return nil, goresterr.NewAPIError(goresterr.InvalidBodyContent, fmt.Sprintf("apply patch failed:%s", err.Error()))
+	}
+
+	patched := s.newResource()
+	patched.SetSchema(s)
+	patched.SetParent(r.GetParent())
+	patched.SetID(r.GetID())
+	patched.SetPatch(patch)
+	patched.SetType(r.GetType())
+	if err := s.fillPatchedResource(patched, data, true); err != nil {
+		return nil, err
+	}
+	return patched, nil
+}
+
+//only the fields modified by patch are validated, applied means data
+//is the whole resource with the patch applied, so the rules involving
+//the modified fields could be checked
+func (s *Schema) fillPatchedResource(r resource.Resource, data []byte, applied bool) *goresterr.APIError {
+	objMap := make(map[string]interface{})
+	if err := json.Unmarshal(data, &objMap); err != nil {
+		return goresterr.NewAPIError(goresterr.InvalidBodyContent, fmt.Sprintf("patched resource isn't a string map:%s", err.Error()))
+	}
+
+	//id and type are decided by url
+	id, typ := r.GetID(), r.GetType()
+	if err := json.Unmarshal(data, r); err != nil {
+		return goresterr.NewAPIError(goresterr.InvalidBodyContent, fmt.Sprintf("patched resource is invalid:%s", err.Error()))
+	}
+	r.SetID(id)
+	r.SetType(typ)
+
+	if s.fields != nil {
+		fields := r.GetPatch().GetFields()
+		patched := objMap
+		//applied data has all the fields, only the modified ones are
+		//regarded as specified by the patch
+		if applied {
+			patched = make(map[string]interface{})
+			for _, name := range fields {
+				if value, ok := objMap[name]; ok {
+					patched[name] = value
 				}
 			}
-			if err := s.fields.Validate(r, objMap); err != nil {
-				return goresterr.NewAPIError(goresterr.InvalidBodyContent, err.Error())
+		}
+		if err := s.fields.ValidateFields(r, patched, fields); err != nil {
+			return validationError(err)
+		}
+		if applied {
+			if err := s.fields.ValidateRules(r, objMap, fields); err != nil {
+				return validationError(err)
 			}
 		}
 	}
 	return nil
 }
 
-func (s *Schema) parseAction(name string, body []byte) (*resource.Action, *goresterr.APIError) {
+func (s *Schema) parseAction(name string, body []byte, onCollection bool) (*resource.Action, *goresterr.APIError) {
 	if s.handler.GetActionHandler() == nil {
 		return nil, goresterr.NewAPIError(goresterr.NotFound,
 			fmt.Sprintf("no handler for action %s", name))
 	}
-	actions := s.resourceKind.GetActions()
-	for i, action := range actions {
+	//resource actions posted to collection are still accepted for
+	//compatibility, collection action with the same name takes precedence
+	if onCollection {
+		if action, err := findAction(s.resourceKind.GetCollectionActions(), s.collectionActionFields, name, body); action != nil || err != nil {
+			return action, err
+		}
+	}
+	if action, err := findAction(s.resourceKind.GetActions(), s.actionFields, name, body); action != nil || err != nil {
+		return action, err
+	}
+	return nil, goresterr.NewAPIError(goresterr.NotFound,
+		fmt.Sprintf("unknown action %s", name))
+}
+
+//nil is returned if there is no action with the name
+func findAction(actions []resource.Action, actionFields map[string]resourcefield.ResourceField, name string, body []byte) (*resource.Action, *goresterr.APIError) {
+	for _, action := range actions {
 		if action.Name == name {
 			if action.Input != nil {
-				if err := json.Unmarshal(body, action.Input); err != nil {
-					return nil, goresterr.NewAPIError(goresterr.InvalidBodyContent,
-						fmt.Sprintf("failed to parse action params: %s", err.Error()))
+				input, err := parseActionInput(action.Input, body, actionFields[name])
+				if err != nil {
+					return nil, err
 				}
+				action.Input = input
 			}
-			a := actions[i]
-			return &a, nil
+			return &action, nil
 		}
 	}
-	return nil, goresterr.NewAPIError(goresterr.NotFound,
-		fmt.Sprintf("unknown action %s", name))
+	return nil, nil
+}
+
+//input of action is a prototype which may be shared by requests,
+//so each request gets a new value with the same type
+func parseActionInput(prototype interface{}, body []byte, fields resourcefield.ResourceField) (interface{}, *goresterr.APIError) {
+	typ := reflect.TypeOf(prototype)
+	isPtr := typ.Kind() == reflect.Ptr
+	if isPtr {
+		typ = typ.Elem()
+	}
+
+	input := reflect.New(typ)
+	if err := json.Unmarshal(body, input.Interface()); err != nil {
+		return nil, goresterr.NewAPIError(goresterr.InvalidBodyContent,
+			fmt.Sprintf("failed to parse action params: %s", err.Error()))
+	}
+
+	if fields != nil {
+		objMap := make(map[string]interface{})
+		if err := json.Unmarshal(body, &objMap); err != nil {
+			return nil, goresterr.NewAPIError(goresterr.InvalidBodyContent,
+				fmt.Sprintf("action params isn't a string map:%s", err.Error()))
+		}
+		if fields.FillDefaults(objMap) {
+			data, _ := json.Marshal(objMap)
+			if err := json.Unmarshal(data, input.Interface()); err != nil {
+				return nil, goresterr.NewAPIError(goresterr.InvalidBodyContent,
+					fmt.Sprintf("failed to parse action params: %s", err.Error()))
+			}
+		}
+		if err := fields.Validate(input.Interface(), objMap); err != nil {
+			return nil, validationError(err)
+		}
+	}
+
+	if isPtr {
+		return input.Interface(), nil
+	} else {
+		return input.Elem().Interface(), nil
+	}
 }
 
 func (s *Schema) AddChild(child *Schema) error {
@@ -179,6 +596,19 @@ func (s *Schema) GetSchema(kind resource.ResourceKind) *Schema {
 	return nil
 }
 
+func (s *Schema) CreateChildResource(parent resource.Resource, name string) (resource.Resource, error) {
+	for _, child := range s.children {
+		if child.resourceName == name {
+			r := child.newResource()
+			r.SetSchema(child)
+			r.SetParent(parent)
+			r.SetType(child.resourceKindName)
+			return r, nil
+		}
+	}
+	return nil, fmt.Errorf("%s has no child %s", s.resourceName, name)
+}
+
 func (s *Schema) GetHandler() resource.Handler {
 	return s.handler
 }
@@ -198,13 +628,19 @@ func (s *Schema) urlIdSegment() string {
 func (s *Schema) generateSelfRoute(parents []*Schema) resource.ResourceRoute {
 	collectionPath := s.generateCollectionPath(parents, nil, "")
 	resourcePath := path.Join(collectionPath, s.urlIdSegment())
+	if s.resourceKind.IsSingleton() {
+		resourcePath = collectionPath
+	}
 	route := resource.NewResourceRoute()
-	for _, method := range resource.GetResourceMethods(s.handler) {
+	for _, method := range resource.GetKindResourceMethods(s.resourceKind, s.handler) {
 		route.AddPathForMethod(method, resourcePath)
 	}
-	for _, method := range resource.GetCollectionMethods(s.handler) {
+	for _, method := range resource.GetKindCollectionMethods(s.resourceKind, s.handler) {
 		route.AddPathForMethod(method, collectionPath)
 	}
+	if s.supportStatusUpdate() {
+		route.AddPathForMethod(http.MethodPut, path.Join(resourcePath, statusSegment))
+	}
 	return route
 }
 
@@ -214,6 +650,9 @@ func (s *Schema) generateCollectionPath(parents []*Schema, ids []string, httpSch
 	segments = append(segments, s.version.GetUrl())
 	for i, parent := range parents {
 		segments = append(segments, parent.resourceName)
+		if parent.resourceKind.IsSingleton() {
+			continue
+		}
 		if ids != nil {
 			segments = append(segments, ids[i])
 		} else {
@@ -235,7 +674,7 @@ func (s *Schema) generateCollectionLink(r resource.Resource, httpSchemeAndHost s
 			ss = append(ss, ps)
 		}
 
-		if pid := parent.GetID(); pid == "" {
+		if pid := parent.GetID(); pid == "" && resource.IsSingleton(parent) == false {
 			return "", fmt.Errorf("%s has no id", parent.GetType())
 		} else {
 			ids = append(ids, pid)
@@ -253,8 +692,9 @@ func (s *Schema) generateCollectionLink(r resource.Resource, httpSchemeAndHost s
 	return s.generateCollectionPath(ss, ids, httpSchemeAndHost), nil
 }
 
+//the collection link of singleton is its self link
 func (s *Schema) AddLinksToResource(r resource.Resource, httpSchemeAndHost string) error {
-	if r.GetID() == "" {
+	if r.GetID() == "" && s.resourceKind.IsSingleton() == false {
 		return fmt.Errorf("resource has no id")
 	}
 
@@ -275,7 +715,21 @@ func (s *Schema) AddLinksToResourceCollection(rs *resource.ResourceCollection, h
 		r.SetLinks(s.generateResourceLinks(r, cl))
 	}
 
-	rs.SetLinks(map[resource.ResourceLinkType]resource.ResourceLink{resource.SelfLink: resource.ResourceLink(cl)})
+	links := map[resource.ResourceLinkType]resource.ResourceLink{resource.SelfLink: resource.ResourceLink(cl)}
+	if s.handler.GetActionHandler() != nil {
+		for _, action := range s.resourceKind.GetCollectionActions() {
+			links[resource.ResourceLinkType(action.Name)] = resource.ResourceLink(cl + "?action=" + url.QueryEscape(action.Name))
+		}
+	}
+	if p := rs.GetPagination(); p != nil {
+		if query := p.NextPageQuery(); query != nil {
+			links[resource.NextLink] = resource.ResourceLink(cl + "?" + query.Encode())
+		}
+		if query := p.PrevPageQuery(); query != nil {
+			links[resource.PrevLink] = resource.ResourceLink(cl + "?" + query.Encode())
+		}
+	}
+	rs.SetLinks(links)
 	return nil
 }
 
@@ -283,7 +737,8 @@ func (s *Schema) generateResourceLinks(r resource.Resource, parentLink string) m
 	links := make(map[resource.ResourceLinkType]resource.ResourceLink)
 	selfLink := path.Join(parentLink, r.GetID())
 	handler := s.GetHandler()
-	if handler.GetListHandler() != nil {
+	singleton := s.resourceKind.IsSingleton()
+	if handler.GetListHandler() != nil && singleton == false {
 		links[resource.CollectionLink] = resource.ResourceLink(parentLink)
 	}
 	if handler.GetGetHandler() != nil {
@@ -292,9 +747,15 @@ func (s *Schema) generateResourceLinks(r resource.Resource, parentLink string) m
 	if handler.GetUpdateHandler() != nil {
 		links[resource.UpdateLink] = resource.ResourceLink(selfLink)
 	}
-	if handler.GetDeleteHandler() != nil {
+	if handler.GetDeleteHandler() != nil && singleton == false {
 		links[resource.RemoveLink] = resource.ResourceLink(selfLink)
 	}
+	if handler.GetPatchHandler() != nil {
+		links[resource.PatchLink] = resource.ResourceLink(selfLink)
+	}
+	if s.supportStatusUpdate() {
+		links[resource.StatusLink] = resource.ResourceLink(path.Join(selfLink, statusSegment))
+	}
 	for _, child := range s.GetChildren() {
 		childName := child.ResourceName()
 		links[resource.ResourceLinkType(childName)] = resource.ResourceLink(path.Join(selfLink, childName))
@@ -302,6 +763,10 @@ func (s *Schema) generateResourceLinks(r resource.Resource, parentLink string) m
 	return links
 }
 
+func (s *Schema) supportStatusUpdate() bool {
+	return s.resourceKind.SupportStatus() && s.handler.GetUpdateStatusHandler() != nil
+}
+
 func (s *Schema) WriteJsonDoc(path string) error {
 	var parents []string
 	for _, parent := range s.resourceKind.GetParents() {
@@ -52,11 +52,11 @@ func (m *SchemaManager) CreateResourceFromRequest(req *http.Request) (resource.R
 	path := multiSlashRegexp.ReplaceAllString(req.URL.EscapedPath(), "/")
 	var action string
 	if req.Method == http.MethodPost {
-		action = req.URL.Query().Get("action")
+		action = req.URL.Query().Get(resource.ActionQueryKey)
 	}
 
 	var body []byte
-	if (req.Method == http.MethodPost || req.Method == http.MethodPut) && req.Body != nil {
+	if (req.Method == http.MethodPost || req.Method == http.MethodPut || req.Method == http.MethodPatch) && req.Body != nil {
 		var err error
 		body, err = ioutil.ReadAll(req.Body)
 		if err != nil {
@@ -67,7 +67,7 @@ func (m *SchemaManager) CreateResourceFromRequest(req *http.Request) (resource.R
 	}
 
 	for _, vs := range m.schemas {
-		if r, err := vs.CreateResourceFromRequest(req.Method, path, body, action); err != nil {
+		if r, err := vs.CreateResourceFromRequest(req.Method, path, body, action, req.Header.Get("Content-Type")); err != nil {
 			return nil, err
 		} else if r != nil {
 			return r, err
@@ -172,6 +172,7 @@ func TestAddResourceCollectionLink(t *testing.T) {
 			pods,
 			map[resource.ResourceLinkType]resource.ResourceLink{
 				resource.SelfLink: resource.ResourceLink("http:/127.0.0.1:5555/apis/testing/v1/clusters/c1/namespaces/n1/deployments/d1/pods"),
+				"evict":           resource.ResourceLink("http:/127.0.0.1:5555/apis/testing/v1/clusters/c1/namespaces/n1/deployments/d1/pods?action=evict"),
 			},
 
 			[]map[resource.ResourceLinkType]resource.ResourceLink{
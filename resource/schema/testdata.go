@@ -81,7 +81,7 @@ func (c Pod) GetParents() []resource.ResourceKind {
 }
 
 type Location struct {
-	NodeName string `json:"nodeName"`
+	NodeName string `json:"nodeName" rest:"required=true,minLen=2"`
 }
 
 func (c Pod) GetActions() []resource.Action {
@@ -93,6 +93,19 @@ func (c Pod) GetActions() []resource.Action {
 	}
 }
 
+type EvictCondition struct {
+	NodeName string `json:"nodeName"`
+}
+
+func (c Pod) GetCollectionActions() []resource.Action {
+	return []resource.Action{
+		resource.Action{
+			Name:  "evict",
+			Input: &EvictCondition{},
+		},
+	}
+}
+
 func (c Pod) CreateDefaultResource() resource.Resource {
 	return &Pod{
 		Count: 20,
@@ -56,7 +56,7 @@ func (s *VersionedSchemas) Import(kind resource.ResourceKind, handler resource.H
 
 var multiSlashRegexp = regexp.MustCompile("//+")
 
-func (s *VersionedSchemas) CreateResourceFromRequest(method, path string, body []byte, action string) (resource.Resource, *goresterr.APIError) {
+func (s *VersionedSchemas) CreateResourceFromRequest(method, path string, body []byte, action, contentType string) (resource.Resource, *goresterr.APIError) {
 	if strings.HasPrefix(path, s.versionUrl) == false {
 		return nil, nil
 	}
@@ -86,7 +86,7 @@ func (s *VersionedSchemas) CreateResourceFromRequest(method, path string, body [
 	}
 
 	for _, schema := range s.toplevelSchemas {
-		if r, err := schema.CreateResourceFromPathSegments(nil, segments, method, action, body); err != nil {
+		if r, err := schema.CreateResourceFromPathSegments(nil, segments, method, action, contentType, body); err != nil {
 			return nil, err
 		} else if r != nil {
 			return r, nil
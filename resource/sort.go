@@ -0,0 +1,131 @@
+package resource
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/zdnscloud/gorest/util"
+)
+
+const (
+	SortQueryKey  = "sort"
+	OrderQueryKey = "order"
+)
+
+type SortOrder string
+
+const (
+	Asc  SortOrder = "asc"
+	Desc SortOrder = "desc"
+)
+
+type SortField struct {
+	Name  string
+	Order SortOrder
+}
+
+type Sort struct {
+	Fields []SortField
+
+	sorted bool
+}
+
+//sort=name,ttl&order=desc, order is applied to all the fields
+//or each field has its own order like order=asc,desc
+//sort field should be json name of the resource field
+func genSort(url *url.URL, r Resource) (*Sort, error) {
+	query := url.Query()
+	sortStr := query.Get(SortQueryKey)
+	orderStr := query.Get(OrderQueryKey)
+	if sortStr == "" {
+		if orderStr != "" {
+			return nil, fmt.Errorf("%s should be used with %s", OrderQueryKey, SortQueryKey)
+		}
+		return nil, nil
+	}
+
+	names := strings.Split(sortStr, ",")
+	var orders []SortOrder
+	if orderStr != "" {
+		for _, o := range strings.Split(orderStr, ",") {
+			order := SortOrder(strings.ToLower(o))
+			if order != Asc && order != Desc {
+				return nil, fmt.Errorf("unknown sort order %s", o)
+			}
+			orders = append(orders, order)
+		}
+		if len(orders) != 1 && len(orders) != len(names) {
+			return nil, fmt.Errorf("sort order count doesn't match sort fields")
+		}
+	}
+
+	typ := reflect.TypeOf(r)
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	fields := util.JsonFieldIndexes(typ)
+	s := &Sort{}
+	for i, name := range names {
+		index, ok := fields[name]
+		if ok == false {
+			return nil, fmt.Errorf("unknown sort field %s", name)
+		}
+		//field like links, struct, slice and map can't be sorted
+		ft := typ.FieldByIndex(index).Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		zero := reflect.New(ft).Elem()
+		if _, err := util.CompareValue(zero, zero); err != nil {
+			return nil, fmt.Errorf("sort field %s isn't comparable", name)
+		}
+		order := Asc
+		if len(orders) == 1 {
+			order = orders[0]
+		} else if len(orders) > 1 {
+			order = orders[i]
+		}
+		s.Fields = append(s.Fields, SortField{Name: name, Order: order})
+	}
+	return s, nil
+}
+
+//list handler which sorts resources by itself should
+//call this function to avoid sort again
+func (s *Sort) SetSorted() {
+	s.sorted = true
+}
+
+func (s *Sort) apply(resources []Resource) error {
+	if s.sorted || len(resources) == 0 {
+		return nil
+	}
+
+	fields := util.JsonFieldIndexes(reflect.TypeOf(resources[0]))
+	var err error
+	sort.SliceStable(resources, func(i, j int) bool {
+		vi := reflect.ValueOf(resources[i]).Elem()
+		vj := reflect.ValueOf(resources[j]).Elem()
+		for _, f := range s.Fields {
+			index, ok := fields[f.Name]
+			if ok == false {
+				err = fmt.Errorf("resource has no field %s", f.Name)
+				return false
+			}
+
+			result, e := util.CompareValue(vi.FieldByIndex(index), vj.FieldByIndex(index))
+			if e != nil {
+				err = fmt.Errorf("sort by field %s failed:%s", f.Name, e.Error())
+				return false
+			}
+			if result != 0 {
+				return (result < 0) == (f.Order == Asc)
+			}
+		}
+		return false
+	})
+	return err
+}
@@ -0,0 +1,226 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zdnscloud/cement/uuid"
+	goresterr "github.com/zdnscloud/gorest/error"
+)
+
+const TaskResourceName = "tasks"
+
+type TaskState string
+
+const (
+	TaskRunning   TaskState = "running"
+	TaskSucceeded TaskState = "succeeded"
+	TaskFailed    TaskState = "failed"
+	TaskCanceled  TaskState = "canceled"
+)
+
+//finished tasks are removed after retention
+var TaskRetention = time.Hour
+
+//task records the execution of an async action, it's a child
+//resource of the resource which the action is applied to
+type Task struct {
+	ResourceBase `json:",inline"`
+	Action       string              `json:"action"`
+	State        TaskState           `json:"state" rest:"options=running|succeeded|failed|canceled"`
+	Progress     Progress            `json:"progress"`
+	Result       interface{}         `json:"result,omitempty"`
+	Error        *goresterr.APIError `json:"error,omitempty"`
+
+	owner      string
+	finishTime time.Time
+	cancel     context.CancelFunc
+}
+
+func (t *Task) isFinished() bool {
+	return t.State != TaskRunning
+}
+
+//task manager runs async actions of one resource kind
+type TaskManager struct {
+	lock  sync.Mutex
+	tasks map[string]*Task
+}
+
+func NewTaskManager() *TaskManager {
+	return &TaskManager{
+		tasks: make(map[string]*Task),
+	}
+}
+
+//run action handler in background, the request of ctx could be
+//finished before the handler returns, so the handler gets a new request
+//whose context is canceled once the task is canceled, if onPanic isn't
+//nil, panic of the handler is recovered and passed to it, and the task
+//fails with server error
+func (m *TaskManager) Run(ctx *Context, handler ActionHandler, onPanic func(interface{})) (*Task, *goresterr.APIError) {
+	id, err := uuid.Gen()
+	if err != nil {
+		return nil, goresterr.NewAPIError(goresterr.ServerError, fmt.Sprintf("generate task id failed:%s", err.Error()))
+	}
+
+	taskCtx, cancel := context.WithCancel(context.Background())
+	task := &Task{
+		Action: ctx.Resource.GetAction().Name,
+		State:  TaskRunning,
+		owner:  ownerKey(ctx.Resource),
+		cancel: cancel,
+	}
+	task.SetID(id)
+	task.SetCreationTimestamp(time.Now())
+
+	m.lock.Lock()
+	m.purgeExpiredTasks()
+	m.tasks[id] = task
+	snapshot := *task
+	m.lock.Unlock()
+
+	actionCtx := ctx.newTaskContext(taskCtx, func(p Progress) {
+		m.updateTask(id, func(t *Task) {
+			t.Progress = p
+		})
+	})
+
+	go func() {
+		defer cancel()
+		if onPanic != nil {
+			defer func() {
+				if r := recover(); r != nil {
+					onPanic(r)
+					m.updateTask(id, func(t *Task) {
+						t.State = TaskFailed
+						t.Error = goresterr.NewAPIError(goresterr.ServerError, "internal server error")
+						t.finishTime = time.Now()
+					})
+				}
+			}()
+		}
+
+		result, err := handler(actionCtx)
+		m.updateTask(id, func(t *Task) {
+			switch {
+			case taskCtx.Err() != nil:
+				t.State = TaskCanceled
+			case err != nil:
+				t.State = TaskFailed
+				t.Error = err
+			default:
+				t.State = TaskSucceeded
+				t.Result = result
+			}
+			t.finishTime = time.Now()
+		})
+	}()
+	return &snapshot, nil
+}
+
+func (m *TaskManager) updateTask(id string, fn func(*Task)) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if t, ok := m.tasks[id]; ok && t.State == TaskRunning {
+		fn(t)
+	}
+}
+
+//expired tasks are purged whenever tasks are accessed
+func (m *TaskManager) purgeExpiredTasks() {
+	now := time.Now()
+	for id, t := range m.tasks {
+		if t.isFinished() && now.Sub(t.finishTime) > TaskRetention {
+			delete(m.tasks, id)
+		}
+	}
+}
+
+func (m *TaskManager) Get(owner Resource, id string) (*Task, bool) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.purgeExpiredTasks()
+	if t, ok := m.tasks[id]; ok && t.owner == ownerKey(owner) {
+		snapshot := *t
+		return &snapshot, true
+	}
+	return nil, false
+}
+
+func (m *TaskManager) List(owner Resource) []*Task {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.purgeExpiredTasks()
+	key := ownerKey(owner)
+	var tasks []*Task
+	for _, t := range m.tasks {
+		if t.owner == key {
+			snapshot := *t
+			tasks = append(tasks, &snapshot)
+		}
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].GetCreationTimestamp().Before(tasks[j].GetCreationTimestamp())
+	})
+	return tasks
+}
+
+//running task is canceled, finished task is removed
+func (m *TaskManager) Delete(owner Resource, id string) bool {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	t, ok := m.tasks[id]
+	if ok == false || t.owner != ownerKey(owner) {
+		return false
+	}
+
+	if t.isFinished() {
+		delete(m.tasks, id)
+	} else {
+		t.cancel()
+		t.State = TaskCanceled
+		t.finishTime = time.Now()
+	}
+	return true
+}
+
+//ids of the resource and its ancestors
+func ownerKey(r Resource) string {
+	var ids []string
+	for ; r != nil; r = r.GetParent() {
+		ids = append(ids, r.GetID())
+	}
+	return strings.Join(ids, "/")
+}
+
+type taskHandler struct {
+	manager *TaskManager
+}
+
+func NewTaskHandler(manager *TaskManager) Handler {
+	h, _ := HandlerAdaptor(&taskHandler{manager: manager})
+	return h
+}
+
+func (h *taskHandler) Get(ctx *Context) (Resource, *goresterr.APIError) {
+	if t, ok := h.manager.Get(ctx.Resource.GetParent(), ctx.Resource.GetID()); ok {
+		return t, nil
+	}
+	return nil, goresterr.NewAPIError(goresterr.NotFound, fmt.Sprintf("task %s doesn't exist", ctx.Resource.GetID()))
+}
+
+func (h *taskHandler) List(ctx *Context) (interface{}, *goresterr.APIError) {
+	return h.manager.List(ctx.Resource.GetParent()), nil
+}
+
+func (h *taskHandler) Delete(ctx *Context) *goresterr.APIError {
+	if h.manager.Delete(ctx.Resource.GetParent(), ctx.Resource.GetID()) {
+		return nil
+	}
+	return goresterr.NewAPIError(goresterr.NotFound, fmt.Sprintf("task %s doesn't exist", ctx.Resource.GetID()))
+}
@@ -0,0 +1,52 @@
+package resource
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	ut "github.com/zdnscloud/cement/unittest"
+	goresterr "github.com/zdnscloud/gorest/error"
+)
+
+func TestTaskManager(t *testing.T) {
+	r := &dumbResource{}
+	r.SetID("d1")
+	r.SetAction(&Action{Name: "upgrade"})
+	req, _ := http.NewRequest("POST", "/apis/testing/v1/dumbresources/d1?action=upgrade", nil)
+	ctx := &Context{
+		Request:  req,
+		Resource: r,
+		params:   map[string]interface{}{"user": "ben"},
+	}
+
+	m := NewTaskManager()
+	done := make(chan struct{})
+	task, err := m.Run(ctx, func(actionCtx *Context) (interface{}, *goresterr.APIError) {
+		defer close(done)
+		//params of the task context aren't shared with the request
+		actionCtx.Set("user", "bob")
+		user, _ := actionCtx.Get("user")
+		return user, nil
+	}, nil)
+	ut.Assert(t, err == nil, "")
+	<-done
+	user, _ := ctx.Get("user")
+	ut.Equal(t, user, "ben")
+
+	for task.State == TaskRunning {
+		time.Sleep(time.Millisecond)
+		task, _ = m.Get(r, task.GetID())
+	}
+	ut.Equal(t, task.State, TaskSucceeded)
+	ut.Equal(t, task.Result, "bob")
+	ut.Equal(t, len(m.List(r)), 1)
+
+	//finished task is purged once it's expired
+	retention := TaskRetention
+	TaskRetention = 0
+	defer func() { TaskRetention = retention }()
+	_, ok := m.Get(r, task.GetID())
+	ut.Assert(t, ok == false, "expired task should be purged")
+	ut.Equal(t, len(m.List(r)), 0)
+}
@@ -0,0 +1,45 @@
+package resource
+
+import (
+	"encoding/json"
+
+	goresterr "github.com/zdnscloud/gorest/error"
+)
+
+const WatchQueryKey = "watch"
+
+type WatchEventType string
+
+const (
+	Added    WatchEventType = "ADDED"
+	Modified WatchEventType = "MODIFIED"
+	Deleted  WatchEventType = "DELETED"
+
+	//events of action
+	InProgress WatchEventType = "PROGRESS"
+	Finished   WatchEventType = "RESULT"
+	Failed     WatchEventType = "ERROR"
+)
+
+//watch handler sends events through channel, and should close
+//the channel when ctx.Request.Context() is done
+type WatchEvent struct {
+	Type     WatchEventType      `json:"type"`
+	Resource Resource            `json:"resource,omitempty"`
+	Progress *Progress           `json:"progress,omitempty"`
+	Result   interface{}         `json:"result,omitempty"`
+	Error    *goresterr.APIError `json:"error,omitempty"`
+}
+
+//writeonly fields of resource are removed
+func (e WatchEvent) MarshalJSON() ([]byte, error) {
+	type event WatchEvent
+	r, err := HideWriteOnlyFields(e.Resource)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(struct {
+		event
+		Resource interface{} `json:"resource,omitempty"`
+	}{event(e), r})
+}
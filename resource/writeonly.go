@@ -0,0 +1,44 @@
+package resource
+
+import (
+	"reflect"
+
+	"github.com/zdnscloud/gorest/resource/schema/resourcefield"
+)
+
+//fields with writeonly tag like password are accepted from client
+//but never returned, resource with such fields is converted to json map,
+//other result is returned directly
+func HideWriteOnlyFields(result interface{}) (interface{}, error) {
+	r, ok := result.(Resource)
+	if ok == false || isNil(result) {
+		return result, nil
+	}
+
+	fields := writeOnlyFields(reflect.TypeOf(r))
+	if fields == nil {
+		return result, nil
+	}
+
+	obj, err := toJsonMap(r)
+	if err != nil {
+		return nil, err
+	}
+	fields.HideWriteOnlyFields(obj)
+	return obj, nil
+}
+
+//field metadata of the resource type if it has writeonly fields,
+//writeonly fields of nested struct, slice and map are included
+func writeOnlyFields(typ reflect.Type) resourcefield.ResourceField {
+	if fields := resourceFields(typ); fields != nil && fields.HasWriteOnlyFields() {
+		return fields
+	}
+	return nil
+}
+
+func hideWriteOnlyFields(obj map[string]interface{}, typ reflect.Type) {
+	if fields := writeOnlyFields(typ); fields != nil {
+		fields.HideWriteOnlyFields(obj)
+	}
+}
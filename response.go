@@ -0,0 +1,49 @@
+package gorest
+
+import (
+	goresterr "github.com/zdnscloud/gorest/error"
+)
+
+//result of a request, which is written to client after all
+//the after handlers are called
+type Response struct {
+	Status int
+	//resource, resource collection or action result
+	Result interface{}
+	//result will be ignored if error isn't nil
+	Error *goresterr.APIError
+
+	//response has been streamed to client by handler
+	streamed bool
+}
+
+func newResponse(status int, result interface{}) *Response {
+	return &Response{
+		Status: status,
+		Result: result,
+	}
+}
+
+func newStreamedResponse(status int) *Response {
+	return &Response{
+		Status:   status,
+		streamed: true,
+	}
+}
+
+func (r *Response) IsStreamed() bool {
+	return r.streamed
+}
+
+func newErrorResponse(err *goresterr.APIError) *Response {
+	return &Response{
+		Status: err.Status,
+		Error:  err,
+	}
+}
+
+func (r *Response) SetError(err *goresterr.APIError) {
+	r.Status = err.Status
+	r.Result = nil
+	r.Error = err
+}
@@ -11,122 +11,262 @@ import (
 	"github.com/zdnscloud/gorest/resource"
 )
 
-func restHandler(ctx *resource.Context) *goresterr.APIError {
+//in debug mode, the result of action is checked against its output type,
+//onPanic recovers the panic of async action which runs in background
+func restHandler(ctx *resource.Context, debug bool, onPanic func(interface{})) (*Response, *goresterr.APIError) {
 	if ctx.Resource.GetAction() != nil {
-		return handleAction(ctx)
+		return handleAction(ctx, debug, onPanic)
 	}
 
 	switch ctx.Method {
 	case http.MethodGet:
+		if ctx.IsWatch() && isCollection(ctx.Resource) {
+			return handleWatch(ctx)
+		}
 		return handleList(ctx)
 	case http.MethodPost:
 		return handleCreate(ctx)
 	case http.MethodPut:
+		if ctx.Resource.IsStatusUpdate() {
+			return handleUpdateStatus(ctx)
+		}
 		return handleUpdate(ctx)
 	case http.MethodDelete:
 		return handleDelete(ctx)
+	case http.MethodPatch:
+		return handlePatch(ctx)
 	default:
-		return goresterr.NewAPIError(goresterr.NotFound, "no found request handler")
+		return nil, goresterr.NewAPIError(goresterr.NotFound, "no found request handler")
 	}
 }
 
-func handleCreate(ctx *resource.Context) *goresterr.APIError {
+func handleCreate(ctx *resource.Context) (*Response, *goresterr.APIError) {
 	schema := ctx.Resource.GetSchema()
 	handler := schema.GetHandler().GetCreateHandler()
 	if handler == nil {
-		return goresterr.NewAPIError(goresterr.NotFound, "no handler for create")
+		return nil, goresterr.NewAPIError(goresterr.NotFound, "no handler for create")
+	}
+
+	if err := validateResource(ctx); err != nil {
+		return nil, err
 	}
 
 	r, err := handler(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	ctx.Resource.SetID(r.GetID())
 	r.SetType(ctx.Resource.GetType())
 	httpSchemeAndHost := path.Join(ctx.Request.URL.Scheme, ctx.Request.URL.Host)
 	if err := schema.AddLinksToResource(r, httpSchemeAndHost); err != nil {
-		return goresterr.NewAPIError(goresterr.ServerError, fmt.Sprintf("generate links failed:%s", err.Error()))
+		return nil, goresterr.NewAPIError(goresterr.ServerError, fmt.Sprintf("generate links failed:%s", err.Error()))
 	}
-	return WriteResponse(ctx.Response, http.StatusCreated, r)
+	return newResponse(http.StatusCreated, r), nil
 }
 
-func handleDelete(ctx *resource.Context) *goresterr.APIError {
+func handleDelete(ctx *resource.Context) (*Response, *goresterr.APIError) {
 	handler := ctx.Resource.GetSchema().GetHandler().GetDeleteHandler()
 	if handler == nil {
-		return goresterr.NewAPIError(goresterr.NotFound, "no handler for delete")
+		return nil, goresterr.NewAPIError(goresterr.NotFound, "no handler for delete")
 	}
 
 	if err := handler(ctx); err != nil {
-		return err
+		return nil, err
 	}
 
 	kind, ok := ctx.Resource.(resource.ResourceKind)
 	if !ok {
-		panic(fmt.Sprintf("resource %v doesn't implement resource kind", ctx.Resource))
+		return nil, goresterr.NewAPIError(goresterr.ServerError,
+			fmt.Sprintf("resource %s doesn't implement resource kind", ctx.Resource.GetType()))
 	}
 	status := http.StatusNoContent
 	if kind.SupportAsyncDelete() {
 		status = http.StatusAccepted
 	}
-	return WriteResponse(ctx.Response, status, nil)
+	return newResponse(status, nil), nil
 }
 
-func handleUpdate(ctx *resource.Context) *goresterr.APIError {
+func handleUpdate(ctx *resource.Context) (*Response, *goresterr.APIError) {
 	schema := ctx.Resource.GetSchema()
 	handler := schema.GetHandler().GetUpdateHandler()
 	if handler == nil {
-		return goresterr.NewAPIError(goresterr.NotFound, "no handler for update")
+		return nil, goresterr.NewAPIError(goresterr.NotFound, "no handler for update")
+	}
+
+	if len(schema.GetImmutableFields()) > 0 || len(schema.GetStatusFields()) > 0 {
+		if err := checkCurrentResource(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if err := validateResource(ctx); err != nil {
+		return nil, err
 	}
 
 	r, err := handler(ctx)
 	if err != nil {
+		return nil, err
+	}
+
+	httpSchemeAndHost := path.Join(ctx.Request.URL.Scheme, ctx.Request.URL.Host)
+	if err := schema.AddLinksToResource(r, httpSchemeAndHost); err != nil {
+		return nil, goresterr.NewAPIError(goresterr.ServerError, fmt.Sprintf("generate links failed:%s", err.Error()))
+	}
+	r.SetType(ctx.Resource.GetType())
+	return newResponse(http.StatusOK, r), nil
+}
+
+func validateResource(ctx *resource.Context) *goresterr.APIError {
+	validator, ok := ctx.Resource.(resource.ResourceValidator)
+	if ok == false {
+		return nil
+	}
+
+	if err := validator.Validate(ctx); err != nil {
+		if apiErr, ok := err.(*goresterr.APIError); ok {
+			return apiErr
+		}
+		return goresterr.NewAPIError(goresterr.InvalidBodyContent, err.Error())
+	}
+	return nil
+}
+
+//stored resource is returned by get handler, immutable fields are
+//checked against it and its status is kept since update doesn't accept
+//status fields, kind with immutable fields must have get handler, so
+//without get handler, only the status isn't kept
+func checkCurrentResource(ctx *resource.Context) *goresterr.APIError {
+	schema := ctx.Resource.GetSchema()
+	getHandler := schema.GetHandler().GetGetHandler()
+	if getHandler == nil {
+		return nil
+	}
+
+	current, err := getHandler(ctx)
+	if err != nil {
+		return err
+	}
+	if isNilResource(current) {
+		return goresterr.NewAPIError(goresterr.NotFound,
+			fmt.Sprintf("%s resource with id %s doesn't exist", ctx.Resource.GetType(), ctx.Resource.GetID()))
+	}
+	if err := schema.CheckImmutableFields(ctx.Resource, current); err != nil {
 		return err
 	}
+	return schema.KeepStatusFields(ctx.Resource, current)
+}
+
+func handleUpdateStatus(ctx *resource.Context) (*Response, *goresterr.APIError) {
+	schema := ctx.Resource.GetSchema()
+	handler := schema.GetHandler().GetUpdateStatusHandler()
+	if handler == nil {
+		return nil, goresterr.NewAPIError(goresterr.NotFound, "no handler for update status")
+	}
+
+	r, err := handler(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	httpSchemeAndHost := path.Join(ctx.Request.URL.Scheme, ctx.Request.URL.Host)
 	if err := schema.AddLinksToResource(r, httpSchemeAndHost); err != nil {
-		return goresterr.NewAPIError(goresterr.ServerError, fmt.Sprintf("generate links failed:%s", err.Error()))
+		return nil, goresterr.NewAPIError(goresterr.ServerError, fmt.Sprintf("generate links failed:%s", err.Error()))
 	}
 	r.SetType(ctx.Resource.GetType())
-	return WriteResponse(ctx.Response, http.StatusOK, r)
+	return newResponse(http.StatusOK, r), nil
 }
 
-func handleList(ctx *resource.Context) *goresterr.APIError {
+func handlePatch(ctx *resource.Context) (*Response, *goresterr.APIError) {
+	schema := ctx.Resource.GetSchema()
+	handler := schema.GetHandler().GetPatchHandler()
+	if handler == nil {
+		return nil, goresterr.NewAPIError(goresterr.NotFound, "no handler for patch")
+	}
+
+	//without get handler, patch is applied to an empty resource
+	var current resource.Resource
+	if getHandler := schema.GetHandler().GetGetHandler(); getHandler != nil {
+		r, err := getHandler(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if isNilResource(r) {
+			return nil, goresterr.NewAPIError(goresterr.NotFound,
+				fmt.Sprintf("%s resource with id %s doesn't exist", ctx.Resource.GetType(), ctx.Resource.GetID()))
+		}
+		current = r
+	}
+
+	patched, err := schema.ApplyPatch(ctx.Resource, current)
+	if err != nil {
+		return nil, err
+	}
+	if current != nil {
+		if err := schema.CheckImmutableFields(patched, current); err != nil {
+			return nil, err
+		}
+	}
+	ctx.Resource = patched
+	if err := validateResource(ctx); err != nil {
+		return nil, err
+	}
+
+	r, err := handler(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	httpSchemeAndHost := path.Join(ctx.Request.URL.Scheme, ctx.Request.URL.Host)
+	if err := schema.AddLinksToResource(r, httpSchemeAndHost); err != nil {
+		return nil, goresterr.NewAPIError(goresterr.ServerError, fmt.Sprintf("generate links failed:%s", err.Error()))
+	}
+	r.SetType(ctx.Resource.GetType())
+	return newResponse(http.StatusOK, r), nil
+}
+
+func handleList(ctx *resource.Context) (*Response, *goresterr.APIError) {
 	var result interface{}
 	schema := ctx.Resource.GetSchema()
-	if ctx.Resource.GetID() == "" {
+	if isCollection(ctx.Resource) {
 		handler := schema.GetHandler().GetListHandler()
 		if handler == nil {
-			return goresterr.NewAPIError(goresterr.NotFound, "no found for list")
+			return nil, goresterr.NewAPIError(goresterr.NotFound, "no found for list")
 		}
 
 		data, err_ := handler(ctx)
 		if err_ != nil {
-			return err_
+			return nil, err_
 		}
 		rc, err := resource.NewResourceCollection(ctx.Resource, data)
 		if err != nil {
-			return goresterr.NewAPIError(goresterr.ServerError, err.Error())
+			return nil, goresterr.NewAPIError(goresterr.ServerError, err.Error())
+		}
+		if s := ctx.GetSort(); s != nil {
+			if err := rc.SetSort(s); err != nil {
+				return nil, goresterr.NewAPIError(goresterr.ServerError, err.Error())
+			}
+		}
+		if p := ctx.GetPagination(); p != nil {
+			rc.SetPagination(p)
 		}
 
 		httpSchemeAndHost := path.Join(ctx.Request.URL.Scheme, ctx.Request.URL.Host)
 		if err := schema.AddLinksToResourceCollection(rc, httpSchemeAndHost); err != nil {
-			return goresterr.NewAPIError(goresterr.ServerError, fmt.Sprintf("generate links failed:%s", err.Error()))
+			return nil, goresterr.NewAPIError(goresterr.ServerError, fmt.Sprintf("generate links failed:%s", err.Error()))
 		}
 		result = rc
 	} else {
 		handler := schema.GetHandler().GetGetHandler()
 		if handler == nil {
-			return goresterr.NewAPIError(goresterr.NotFound, "no found for list")
+			return nil, goresterr.NewAPIError(goresterr.NotFound, "no found for list")
 		}
 		r, err := handler(ctx)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		if r == nil || (reflect.ValueOf(r).Kind() == reflect.Ptr && reflect.ValueOf(r).IsNil()) {
-			return goresterr.NewAPIError(goresterr.NotFound,
+		if isNilResource(r) {
+			return nil, goresterr.NewAPIError(goresterr.NotFound,
 				fmt.Sprintf("%s resource with id %s doesn't exist", ctx.Resource.GetType(), ctx.Resource.GetID()))
 		} else {
 			//the resource handler returns mayn't include schema
@@ -134,32 +274,95 @@ func handleList(ctx *resource.Context) *goresterr.APIError {
 			r.SetParent(ctx.Resource.GetParent())
 			httpSchemeAndHost := path.Join(ctx.Request.URL.Scheme, ctx.Request.URL.Host)
 			if err := schema.AddLinksToResource(r, httpSchemeAndHost); err != nil {
-				return goresterr.NewAPIError(goresterr.ServerError, fmt.Sprintf("generate links failed:%s", err.Error()))
+				return nil, goresterr.NewAPIError(goresterr.ServerError, fmt.Sprintf("generate links failed:%s", err.Error()))
 			}
 			r.SetType(ctx.Resource.GetType())
 		}
-		result = r
+
+		expanded, err := expandResource(ctx, r, ctx.GetExpandPaths())
+		if err != nil {
+			return nil, err
+		}
+		result = expanded
 	}
 
-	return WriteResponse(ctx.Response, http.StatusOK, result)
+	return newResponse(http.StatusOK, result), nil
 }
 
-func handleAction(ctx *resource.Context) *goresterr.APIError {
+func handleAction(ctx *resource.Context, debug bool, onPanic func(interface{})) (*Response, *goresterr.APIError) {
 	handler := ctx.Resource.GetSchema().GetHandler().GetActionHandler()
 	if handler == nil {
-		return goresterr.NewAPIError(goresterr.NotFound, "no handler for action")
+		return nil, goresterr.NewAPIError(goresterr.NotFound, "no handler for action")
+	}
+
+	if debug {
+		handler = checkActionOutput(handler)
+	}
+
+	if ctx.Resource.GetAction().Async {
+		return handleAsyncAction(ctx, handler, onPanic)
 	}
 
 	result, err := handler(ctx)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	return newResponse(http.StatusOK, result), nil
+}
+
+func checkActionOutput(handler resource.ActionHandler) resource.ActionHandler {
+	return func(ctx *resource.Context) (interface{}, *goresterr.APIError) {
+		result, err := handler(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := ctx.Resource.GetAction().CheckOutput(result); err != nil {
+			return nil, goresterr.NewAPIError(goresterr.ServerError, err.Error())
+		}
+		return result, nil
+	}
+}
+
+func handleAsyncAction(ctx *resource.Context, handler resource.ActionHandler, onPanic func(interface{})) (*Response, *goresterr.APIError) {
+	schema := ctx.Resource.GetSchema()
+	tasks := schema.GetTaskManager()
+	if tasks == nil {
+		return nil, goresterr.NewAPIError(goresterr.ServerError, "no task manager for async action")
 	}
 
-	return WriteResponse(ctx.Response, http.StatusOK, result)
+	task, err := tasks.Run(ctx, handler, onPanic)
+	if err != nil {
+		return nil, err
+	}
+
+	child, err_ := schema.CreateChildResource(ctx.Resource, resource.TaskResourceName)
+	if err_ != nil {
+		return nil, goresterr.NewAPIError(goresterr.ServerError, err_.Error())
+	}
+	task.SetSchema(child.GetSchema())
+	task.SetParent(ctx.Resource)
+	task.SetType(child.GetType())
+	httpSchemeAndHost := path.Join(ctx.Request.URL.Scheme, ctx.Request.URL.Host)
+	if err := child.GetSchema().AddLinksToResource(task, httpSchemeAndHost); err != nil {
+		return nil, goresterr.NewAPIError(goresterr.ServerError, fmt.Sprintf("add resource links failed:%s", err.Error()))
+	}
+	ctx.Response.Header().Set("Location", string(task.GetLinks()[resource.SelfLink]))
+	return newResponse(http.StatusAccepted, task), nil
+}
+
+func isCollection(r resource.Resource) bool {
+	return r.GetID() == "" && resource.IsSingleton(r) == false
+}
+
+func isNilResource(r resource.Resource) bool {
+	return r == nil || (reflect.ValueOf(r).Kind() == reflect.Ptr && reflect.ValueOf(r).IsNil())
 }
 
 const ContentTypeKey = "Content-Type"
 
+//writeonly fields of resource are removed
 func WriteResponse(resp http.ResponseWriter, status int, result interface{}) *goresterr.APIError {
 	resp.Header().Set(ContentTypeKey, "application/json")
 	resp.WriteHeader(status)
@@ -167,6 +370,11 @@ func WriteResponse(resp http.ResponseWriter, status int, result interface{}) *go
 		return nil
 	}
 
+	result, err := resource.HideWriteOnlyFields(result)
+	if err != nil {
+		return goresterr.NewAPIError(goresterr.ServerError, fmt.Sprintf("hide writeonly fields failed:%s", err.Error()))
+	}
+
 	body, err := json.Marshal(result)
 	if err != nil {
 		return goresterr.NewAPIError(goresterr.ServerError, fmt.Sprintf("marshal failed:%s", err.Error()))
@@ -1,8 +1,12 @@
 package gorest
 
 import (
+	"context"
+	"fmt"
 	"net/http"
+	"sync"
 
+	"github.com/gorilla/websocket"
 	goresterr "github.com/zdnscloud/gorest/error"
 	"github.com/zdnscloud/gorest/resource"
 )
@@ -10,36 +14,179 @@ import (
 type HandlerFunc func(*resource.Context) *goresterr.APIError
 type HandlersChain []HandlerFunc
 
+//after handler could inspect or modify the response
+//including the response generated from the error of pre handlers,
+//resource of the context is nil if it can't be created from request
+type AfterHandlerFunc func(*resource.Context, *Response)
+type AfterHandlersChain []AfterHandlerFunc
+
 type Server struct {
-	Schemas  resource.SchemaManager
-	handlers HandlersChain
+	Schemas       resource.SchemaManager
+	handlers      HandlersChain
+	afterHandlers AfterHandlersChain
+	logger        Logger
+	recovery      bool
+	debug         bool
+	upgrader      websocket.Upgrader
+
+	lock     sync.Mutex
+	shutdown bool
+	done     chan struct{}
+	streams  sync.WaitGroup
 }
 
 func NewAPIServer(schemas resource.SchemaManager) *Server {
 	return &Server{
-		Schemas: schemas,
+		Schemas:  schemas,
+		logger:   stdLogger{},
+		recovery: true,
+		done:     make(chan struct{}),
 	}
 }
 
+func (s *Server) SetLogger(logger Logger) {
+	s.logger = logger
+}
+
+//panic will be converted into server error by default,
+//disable it to let panic propagate, which is useful in test
+func (s *Server) SetRecovery(enable bool) {
+	s.recovery = enable
+}
+
+//debug mode checks the result of handler, which helps to find bug
+//in handler but costs more
+func (s *Server) SetDebug(enable bool) {
+	s.debug = enable
+}
+
 func (s *Server) Use(h HandlerFunc) {
 	s.handlers = append(s.handlers, h)
 }
 
+func (s *Server) UseAfter(h AfterHandlerFunc) {
+	s.afterHandlers = append(s.afterHandlers, h)
+}
+
+//close watch streams and websocket connections, since http.Server
+//doesn't track hijacked connections and waits for streaming requests,
+//it should be called before the http server shuts down
+func (s *Server) Shutdown() {
+	s.lock.Lock()
+	if s.shutdown == false {
+		s.shutdown = true
+		close(s.done)
+	}
+	s.lock.Unlock()
+	s.streams.Wait()
+}
+
+func (s *Server) isShutdown() bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.shutdown
+}
+
+//request of long-lived stream is canceled when server shuts down
+func (s *Server) bindStream(ctx *resource.Context) (func(), bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.shutdown {
+		return nil, false
+	}
+
+	reqCtx, cancel := context.WithCancel(ctx.Request.Context())
+	ctx.Request = ctx.Request.WithContext(reqCtx)
+	s.streams.Add(1)
+	go func() {
+		select {
+		case <-s.done:
+		case <-reqCtx.Done():
+		}
+		cancel()
+	}()
+	return func() {
+		cancel()
+		s.streams.Done()
+	}, true
+}
+
 func (s *Server) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if s.recovery {
+		defer s.recoverPanic(rw, req)
+	}
+
+	if websocket.IsWebSocketUpgrade(req) {
+		s.serveWebSocket(rw, req)
+		return
+	}
+
 	ctx, err := resource.NewContext(rw, req, s.Schemas)
 	if err != nil {
-		WriteResponse(rw, err.Status, err)
+		s.writeResponse(rw, resource.NewEmptyContext(rw, req, s.Schemas), newErrorResponse(err))
 		return
 	}
 
-	for _, h := range s.handlers {
-		if err := h(ctx); err != nil {
+	if ctx.IsWatch() {
+		release, ok := s.bindStream(ctx)
+		if !ok {
+			err := goresterr.NewAPIError(goresterr.ServiceUnavailable, "server is shutting down")
+			s.writeResponse(rw, ctx, newErrorResponse(err))
+			return
+		}
+		defer release()
+	}
+
+	s.writeResponse(rw, ctx, s.handle(ctx))
+}
+
+//every response goes through the after handlers before it's written
+func (s *Server) writeResponse(rw http.ResponseWriter, ctx *resource.Context, resp *Response) {
+	for _, h := range s.afterHandlers {
+		h(ctx, resp)
+	}
+
+	if resp.IsStreamed() {
+		return
+	}
+
+	if resp.Error != nil {
+		WriteResponse(rw, resp.Status, resp.Error)
+		return
+	}
+
+	result := resp.Result
+	if selector := ctx.GetFieldSelector(); selector != nil {
+		var err error
+		if result, err = selector.Apply(result); err != nil {
+			err := goresterr.NewAPIError(goresterr.ServerError, fmt.Sprintf("select fields failed:%s", err.Error()))
 			WriteResponse(rw, err.Status, err)
 			return
 		}
 	}
 
-	if err := restHandler(ctx); err != nil {
+	if err := WriteResponse(rw, resp.Status, result); err != nil {
 		WriteResponse(rw, err.Status, err)
 	}
 }
+
+func (s *Server) handle(ctx *resource.Context) *Response {
+	if err := s.runHandlers(ctx); err != nil {
+		return newErrorResponse(err)
+	}
+
+	resp, err := restHandler(ctx, s.debug, s.actionPanicHandler(ctx))
+	if err != nil {
+		return newErrorResponse(err)
+	}
+	return resp
+}
+
+func (s *Server) runHandlers(ctx *resource.Context) *goresterr.APIError {
+	for _, h := range s.handlers {
+		if err := h(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
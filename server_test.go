@@ -1,14 +1,24 @@
 package gorest
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/gorilla/websocket"
 	ut "github.com/zdnscloud/cement/unittest"
 	goresterr "github.com/zdnscloud/gorest/error"
 	"github.com/zdnscloud/gorest/resource"
 	"github.com/zdnscloud/gorest/resource/schema"
+	"github.com/zdnscloud/gorest/resource/schema/resourcedoc"
+	"github.com/zdnscloud/gorest/resource/schema/resourcefield/validator"
+	"github.com/zdnscloud/gorest/util"
 )
 
 var (
@@ -83,3 +93,1443 @@ func TestContextPassChain(t *testing.T) {
 	s.ServeHTTP(w, req)
 	ut.Equal(t, w.Code, http.StatusNoContent)
 }
+
+type Baz struct {
+	resource.ResourceBase `json:",inline"`
+	Name                  string `json:"name" rest:"required=true"`
+	Count                 int    `json:"count" rest:"min=1,max=10"`
+}
+
+type bazHandler struct {
+	baz *Baz
+}
+
+func (h *bazHandler) Get(ctx *resource.Context) (resource.Resource, *goresterr.APIError) {
+	return h.baz, nil
+}
+
+func (h *bazHandler) Patch(ctx *resource.Context) (resource.Resource, *goresterr.APIError) {
+	h.baz = ctx.Resource.(*Baz)
+	return h.baz, nil
+}
+
+func TestPatch(t *testing.T) {
+	schemas := schema.NewSchemaManager()
+	baz := &Baz{Name: "b1", Count: 1}
+	baz.SetID("b1")
+	handler := &bazHandler{baz: baz}
+	schemas.MustImport(&version, Baz{}, handler)
+	s := NewAPIServer(schemas)
+
+	req, _ := http.NewRequest("PATCH", "/apis/testing/v1/bazs/b1", strings.NewReader(`{"count":5}`))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, http.StatusOK)
+	ut.Equal(t, handler.baz.Name, "b1")
+	ut.Equal(t, handler.baz.Count, 5)
+	ut.Equal(t, string(handler.baz.GetLinks()[resource.PatchLink]), "/apis/testing/v1/bazs/b1")
+
+	req, _ = http.NewRequest("PATCH", "/apis/testing/v1/bazs/b1", strings.NewReader(`[{"op":"replace","path":"/count","value":20}]`))
+	req.Header.Set("Content-Type", "application/json-patch+json")
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, goresterr.InvalidBodyContent.Status)
+	ut.Equal(t, handler.baz.Count, 5)
+}
+
+func TestAfterHandler(t *testing.T) {
+	schemas := schema.NewSchemaManager()
+	baz := &Baz{Name: "b1", Count: 1}
+	baz.SetID("b1")
+	schemas.MustImport(&version, Baz{}, &bazHandler{baz: baz})
+	s := NewAPIServer(schemas)
+
+	var results []interface{}
+	var errs []*goresterr.APIError
+	s.UseAfter(func(ctx *resource.Context, resp *Response) {
+		results = append(results, resp.Result)
+		errs = append(errs, resp.Error)
+	})
+	s.UseAfter(func(ctx *resource.Context, resp *Response) {
+		if resp.Error != nil && resp.Error.ErrorCode == goresterr.NotFound {
+			resp.Status = http.StatusGone
+		}
+	})
+
+	req, _ := http.NewRequest("GET", "/apis/testing/v1/bazs/b1", nil)
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, http.StatusOK)
+	ut.Equal(t, results[0].(*Baz).Name, "b1")
+	ut.Assert(t, errs[0] == nil, "")
+
+	req, _ = http.NewRequest("DELETE", "/apis/testing/v1/bazs/b1", nil)
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, http.StatusGone)
+	ut.Assert(t, results[1] == nil, "")
+	ut.Equal(t, errs[1].ErrorCode, goresterr.NotFound)
+
+	//error of unknown resource goes through after handlers as well
+	req, _ = http.NewRequest("GET", "/apis/testing/v1/unknowns", nil)
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, http.StatusGone)
+	ut.Equal(t, errs[2].ErrorCode, goresterr.NotFound)
+}
+
+type panicHandler struct{}
+
+func (h *panicHandler) List(ctx *resource.Context) (interface{}, *goresterr.APIError) {
+	panic("list failed")
+}
+
+type testLogger struct {
+	logs []string
+}
+
+func (l *testLogger) Error(format string, args ...interface{}) error {
+	l.logs = append(l.logs, fmt.Sprintf(format, args...))
+	return nil
+}
+
+func TestPanicRecovery(t *testing.T) {
+	schemas := schema.NewSchemaManager()
+	schemas.MustImport(&version, Foo{}, &panicHandler{})
+	s := NewAPIServer(schemas)
+	logger := &testLogger{}
+	s.SetLogger(logger)
+
+	req, _ := http.NewRequest("GET", "/apis/testing/v1/foos", nil)
+	req.Header.Set(RequestIDKey, "r1")
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, http.StatusInternalServerError)
+	ut.Equal(t, w.Header().Get(RequestIDKey), "r1")
+	var apiErr goresterr.APIError
+	ut.Assert(t, json.Unmarshal(w.Body.Bytes(), &apiErr) == nil, "")
+	ut.Equal(t, apiErr.ErrorCode, goresterr.ServerError)
+	ut.Assert(t, strings.Contains(apiErr.Message, "r1"), "")
+	ut.Equal(t, len(logger.logs), 1)
+	ut.Assert(t, strings.Contains(logger.logs[0], "list failed"), "")
+
+	req.Header.Del(RequestIDKey)
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, http.StatusInternalServerError)
+	ut.Assert(t, w.Header().Get(RequestIDKey) != "", "")
+
+	s.SetRecovery(false)
+	defer func() {
+		ut.Equal(t, recover(), "list failed")
+	}()
+	s.ServeHTTP(httptest.NewRecorder(), req)
+}
+
+type bazListHandler struct{}
+
+func (h *bazListHandler) List(ctx *resource.Context) (interface{}, *goresterr.APIError) {
+	var bazs []*Baz
+	for i := 0; i < 5; i++ {
+		baz := &Baz{Name: fmt.Sprintf("b%d", i)}
+		baz.SetID(baz.Name)
+		bazs = append(bazs, baz)
+	}
+	return bazs, nil
+}
+
+func TestPagination(t *testing.T) {
+	schemas := schema.NewSchemaManager()
+	schemas.MustImport(&version, Baz{}, &bazListHandler{})
+	s := NewAPIServer(schemas)
+
+	var rc *resource.ResourceCollection
+	s.UseAfter(func(ctx *resource.Context, resp *Response) {
+		rc, _ = resp.Result.(*resource.ResourceCollection)
+	})
+
+	req, _ := http.NewRequest("GET", "/apis/testing/v1/bazs?limit=2", nil)
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, http.StatusOK)
+	ut.Equal(t, len(rc.Resources), 2)
+	ut.Equal(t, rc.Pagination.Total, 5)
+	next := string(rc.Links[resource.NextLink])
+	ut.Assert(t, strings.HasPrefix(next, "/apis/testing/v1/bazs?"), "")
+	_, ok := rc.Links[resource.PrevLink]
+	ut.Assert(t, !ok, "")
+
+	req, _ = http.NewRequest("GET", next, nil)
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, rc.Resources[0].GetID(), "b2")
+	ut.Equal(t, string(rc.Links[resource.PrevLink]), "/apis/testing/v1/bazs?limit=2")
+
+	req, _ = http.NewRequest("GET", "/apis/testing/v1/bazs?limit=-1", nil)
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, goresterr.InvalidFormat.Status)
+}
+
+func TestFieldSelector(t *testing.T) {
+	schemas := schema.NewSchemaManager()
+	baz := &Baz{Name: "b1", Count: 1}
+	baz.SetID("b1")
+	schemas.MustImport(&version, Baz{}, &bazHandler{baz: baz})
+	s := NewAPIServer(schemas)
+
+	cases := []struct {
+		url    string
+		fields []string
+	}{
+		{"/apis/testing/v1/bazs/b1?fields=name", []string{"id", "links", "name", "type"}},
+		{"/apis/testing/v1/bazs/b1?exclude=name,count", []string{"creationTimestamp", "deletionTimestamp", "id", "links", "type"}},
+		{"/apis/testing/v1/bazs/b1?fields=name,count&exclude=count", []string{"id", "links", "name", "type"}},
+	}
+	for _, tc := range cases {
+		req, _ := http.NewRequest("GET", tc.url, nil)
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, req)
+		ut.Equal(t, w.Code, http.StatusOK)
+		obj := make(map[string]interface{})
+		ut.Assert(t, json.Unmarshal(w.Body.Bytes(), &obj) == nil, "")
+		var fields []string
+		for name := range obj {
+			fields = append(fields, name)
+		}
+		sort.Strings(fields)
+		ut.Equal(t, fields, tc.fields)
+	}
+
+	req, _ := http.NewRequest("GET", "/apis/testing/v1/bazs/b1?fields=unknown", nil)
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, goresterr.InvalidFormat.Status)
+
+	schemas = schema.NewSchemaManager()
+	schemas.MustImport(&version, Baz{}, &bazListHandler{})
+	s = NewAPIServer(schemas)
+	req, _ = http.NewRequest("GET", "/apis/testing/v1/bazs?fields=count", nil)
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	var rc struct {
+		Data []map[string]interface{} `json:"data"`
+	}
+	ut.Assert(t, json.Unmarshal(w.Body.Bytes(), &rc) == nil, "")
+	ut.Equal(t, len(rc.Data), 5)
+	for _, r := range rc.Data {
+		_, hasName := r["name"]
+		_, hasCount := r["count"]
+		ut.Assert(t, !hasName && hasCount, "")
+		ut.Assert(t, r["id"] != nil && r["links"] != nil, "")
+	}
+}
+
+type Tree struct {
+	resource.ResourceBase `json:",inline"`
+	Name                  string `json:"name"`
+}
+
+type Branch struct {
+	resource.ResourceBase `json:",inline"`
+}
+
+func (b Branch) GetParents() []resource.ResourceKind {
+	return []resource.ResourceKind{Tree{}}
+}
+
+type Leaf struct {
+	resource.ResourceBase `json:",inline"`
+}
+
+func (l Leaf) GetParents() []resource.ResourceKind {
+	return []resource.ResourceKind{Branch{}}
+}
+
+type Root struct {
+	resource.ResourceBase `json:",inline"`
+}
+
+func (r Root) GetParents() []resource.ResourceKind {
+	return []resource.ResourceKind{Tree{}}
+}
+
+func (r Root) SupportExpand() bool {
+	return false
+}
+
+type treeHandler struct{}
+
+func (h *treeHandler) Get(ctx *resource.Context) (resource.Resource, *goresterr.APIError) {
+	tree := &Tree{Name: "t1"}
+	tree.SetID("t1")
+	return tree, nil
+}
+
+type branchHandler struct{}
+
+func (h *branchHandler) List(ctx *resource.Context) (interface{}, *goresterr.APIError) {
+	var branches []*Branch
+	for i := 0; i < 2; i++ {
+		b := &Branch{}
+		b.SetID(fmt.Sprintf("%s-b%d", ctx.Resource.GetParent().GetID(), i))
+		branches = append(branches, b)
+	}
+	return branches, nil
+}
+
+type leafHandler struct{}
+
+func (h *leafHandler) List(ctx *resource.Context) (interface{}, *goresterr.APIError) {
+	l := &Leaf{}
+	l.SetID(ctx.Resource.GetParent().GetID() + "-l0")
+	return []*Leaf{l}, nil
+}
+
+func TestExpand(t *testing.T) {
+	schemas := schema.NewSchemaManager()
+	schemas.MustImport(&version, Tree{}, &treeHandler{})
+	schemas.MustImport(&version, Branch{}, &branchHandler{})
+	schemas.MustImport(&version, Leaf{}, &leafHandler{})
+	schemas.MustImport(&version, Root{}, &leafHandler{})
+	s := NewAPIServer(schemas)
+
+	req, _ := http.NewRequest("GET", "/apis/testing/v1/trees/t1?expand=branches.leafs", nil)
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, http.StatusOK)
+	var tree struct {
+		Name     string `json:"name"`
+		Branches struct {
+			Data []struct {
+				ID    string                                  `json:"id"`
+				Links map[resource.ResourceLinkType]string    `json:"links"`
+				Leafs struct{ Data []map[string]interface{} } `json:"leafs"`
+			} `json:"data"`
+		} `json:"branches"`
+	}
+	ut.Assert(t, json.Unmarshal(w.Body.Bytes(), &tree) == nil, "")
+	ut.Equal(t, tree.Name, "t1")
+	ut.Equal(t, len(tree.Branches.Data), 2)
+	ut.Equal(t, tree.Branches.Data[1].ID, "t1-b1")
+	ut.Equal(t, tree.Branches.Data[1].Links[resource.CollectionLink], "/apis/testing/v1/trees/t1/branches")
+	ut.Equal(t, tree.Branches.Data[1].Leafs.Data[0]["id"], "t1-b1-l0")
+
+	for _, expand := range []string{"unknown", "roots", "branches.leafs.unknown.unknown"} {
+		req, _ := http.NewRequest("GET", "/apis/testing/v1/trees/t1?expand="+expand, nil)
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, req)
+		ut.Equal(t, w.Code, goresterr.InvalidFormat.Status)
+	}
+}
+
+type bazWatchHandler struct {
+	watched chan struct{}
+}
+
+func (h *bazWatchHandler) Get(ctx *resource.Context) (resource.Resource, *goresterr.APIError) {
+	return nil, nil
+}
+
+func (h *bazWatchHandler) Watch(ctx *resource.Context) (<-chan resource.WatchEvent, *goresterr.APIError) {
+	ch := make(chan resource.WatchEvent)
+	go func() {
+		defer close(ch)
+		for _, typ := range []resource.WatchEventType{resource.Added, resource.Modified, resource.Deleted} {
+			baz := &Baz{Name: "b1"}
+			baz.SetID("b1")
+			select {
+			case ch <- resource.WatchEvent{Type: typ, Resource: baz}:
+			case <-ctx.Request.Context().Done():
+				return
+			}
+		}
+		if h.watched != nil {
+			<-ctx.Request.Context().Done()
+			close(h.watched)
+		}
+	}()
+	return ch, nil
+}
+
+func TestWatch(t *testing.T) {
+	schemas := schema.NewSchemaManager()
+	schemas.MustImport(&version, Baz{}, &bazWatchHandler{})
+	s := NewAPIServer(schemas)
+
+	req, _ := http.NewRequest("GET", "/apis/testing/v1/bazs?watch=true", nil)
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, http.StatusOK)
+	ut.Equal(t, w.Header().Get(ContentTypeKey), "text/event-stream")
+
+	events := strings.Split(strings.TrimSpace(w.Body.String()), "\n\n")
+	ut.Equal(t, len(events), 3)
+	for i, typ := range []string{"ADDED", "MODIFIED", "DELETED"} {
+		lines := strings.Split(events[i], "\n")
+		ut.Equal(t, lines[0], "event: "+typ)
+		var e struct {
+			Type     string                 `json:"type"`
+			Resource map[string]interface{} `json:"resource"`
+		}
+		ut.Assert(t, json.Unmarshal([]byte(strings.TrimPrefix(lines[1], "data: ")), &e) == nil, "")
+		ut.Equal(t, e.Type, typ)
+		ut.Equal(t, e.Resource["id"], "b1")
+		links := e.Resource["links"].(map[string]interface{})
+		ut.Equal(t, links["self"], "/apis/testing/v1/bazs/b1")
+	}
+
+}
+
+func TestWatchHeartbeatAndDisconnect(t *testing.T) {
+	oldInterval := WatchHeartbeatInterval
+	WatchHeartbeatInterval = 10 * time.Millisecond
+	defer func() { WatchHeartbeatInterval = oldInterval }()
+
+	schemas := schema.NewSchemaManager()
+	handler := &bazWatchHandler{watched: make(chan struct{})}
+	schemas.MustImport(&version, Baz{}, handler)
+	s := NewAPIServer(schemas)
+
+	reqCtx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequest("GET", "/apis/testing/v1/bazs?watch=true", nil)
+	req = req.WithContext(reqCtx)
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Assert(t, strings.Contains(w.Body.String(), ": heartbeat\n\n"), "")
+
+	select {
+	case <-handler.watched:
+	case <-time.After(time.Second):
+		t.Fatal("watch handler isn't notified when client disconnects")
+	}
+}
+
+func dialWebSocket(t *testing.T, server *httptest.Server, path string) *websocket.Conn {
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + path
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	ut.Assert(t, err == nil, "dial websocket failed:%v", err)
+	return conn
+}
+
+func TestWebSocketWatch(t *testing.T) {
+	schemas := schema.NewSchemaManager()
+	schemas.MustImport(&version, Baz{}, &bazWatchHandler{})
+	server := httptest.NewServer(NewAPIServer(schemas))
+	defer server.Close()
+
+	conn := dialWebSocket(t, server, "/apis/testing/v1/bazs")
+	defer conn.Close()
+	for _, typ := range []string{"ADDED", "MODIFIED", "DELETED"} {
+		var e struct {
+			Type     string                 `json:"type"`
+			Resource map[string]interface{} `json:"resource"`
+		}
+		ut.Assert(t, conn.ReadJSON(&e) == nil, "")
+		ut.Equal(t, e.Type, typ)
+		links := e.Resource["links"].(map[string]interface{})
+		ut.Equal(t, links["self"], "/apis/testing/v1/bazs/b1")
+	}
+	_, _, err := conn.ReadMessage()
+	ut.Assert(t, websocket.IsCloseError(err, websocket.CloseNormalClosure), "")
+
+	//singleton has no collection to watch
+	schemas.MustImport(&version, Tree{}, &treeHandler{})
+	schemas.MustImport(&version, Dnsconfig{}, &dnsconfigHandler{config: &Dnsconfig{}})
+	conn2 := dialWebSocket(t, server, "/apis/testing/v1/trees/t1/dnsconfig")
+	defer conn2.Close()
+	var e resource.WatchEvent
+	ut.Assert(t, conn2.ReadJSON(&e) == nil, "")
+	ut.Equal(t, e.Type, resource.Failed)
+	ut.Equal(t, e.Error.Code, goresterr.MethodNotAllowed.Code)
+}
+
+type panicWatchHandler struct{}
+
+func (h *panicWatchHandler) Watch(ctx *resource.Context) (<-chan resource.WatchEvent, *goresterr.APIError) {
+	panic("watch failed")
+}
+
+func TestWebSocketErrors(t *testing.T) {
+	schemas := schema.NewSchemaManager()
+	schemas.MustImport(&version, Baz{}, &panicWatchHandler{})
+	schemas.MustImport(&version, Upgrade{}, &upgradeHandler{})
+	s := NewAPIServer(schemas)
+	logger := &testLogger{}
+	s.SetLogger(logger)
+	errs := make(chan goresterr.ErrorCode, 2)
+	s.UseAfter(func(ctx *resource.Context, resp *Response) {
+		errs <- resp.Error.ErrorCode
+	})
+	server := httptest.NewServer(s)
+	defer server.Close()
+
+	readError := func(conn *websocket.Conn, code int) goresterr.ErrorCode {
+		defer conn.Close()
+		var e resource.WatchEvent
+		ut.Assert(t, conn.ReadJSON(&e) == nil, "")
+		ut.Equal(t, e.Type, resource.Failed)
+		_, _, err := conn.ReadMessage()
+		ut.Assert(t, websocket.IsCloseError(err, code), "unexpected close %v", err)
+		return e.Error.ErrorCode
+	}
+
+	//error of unknown resource goes through after handlers
+	conn := dialWebSocket(t, server, "/apis/testing/v1/unknowns")
+	ut.Equal(t, readError(conn, websocket.CloseNormalClosure), goresterr.NotFound)
+	ut.Equal(t, <-errs, goresterr.NotFound)
+
+	conn = dialWebSocket(t, server, "/apis/testing/v1/upgrades/u1?action=start")
+	ut.Assert(t, conn.WriteJSON(UpgradeInput{Version: "v2"}) == nil, "")
+	ut.Equal(t, readError(conn, websocket.CloseNormalClosure), goresterr.InvalidAction)
+	ut.Equal(t, <-errs, goresterr.InvalidAction)
+
+	//panic is sent through close frame instead of http response
+	conn = dialWebSocket(t, server, "/apis/testing/v1/bazs")
+	ut.Equal(t, readError(conn, websocket.CloseInternalServerErr), goresterr.ServerError)
+	ut.Equal(t, len(logger.logs), 1)
+	ut.Assert(t, strings.Contains(logger.logs[0], "watch failed"), "")
+}
+
+type Backup struct {
+	resource.ResourceBase `json:",inline"`
+}
+
+type BackupInput struct {
+	Target string `json:"target"`
+}
+
+func (b Backup) GetActions() []resource.Action {
+	return []resource.Action{
+		resource.Action{
+			Name:  "run",
+			Input: &BackupInput{},
+		},
+	}
+}
+
+func (b Backup) GetCollectionActions() []resource.Action {
+	return []resource.Action{
+		resource.Action{
+			Name: "prune",
+		},
+	}
+}
+
+type backupHandler struct{}
+
+func (h *backupHandler) Action(ctx *resource.Context) (interface{}, *goresterr.APIError) {
+	if ctx.Resource.GetID() == "" {
+		return ctx.Resource.GetAction().Name, nil
+	}
+
+	input := ctx.Resource.GetAction().Input.(*BackupInput)
+	if input.Target == "" {
+		return nil, goresterr.NewAPIError(goresterr.InvalidAction, "empty target")
+	}
+	ctx.ReportProgress(50, "copying")
+	ctx.ReportProgress(100, "done")
+	return input.Target, nil
+}
+
+func TestWebSocketActionProgress(t *testing.T) {
+	schemas := schema.NewSchemaManager()
+	schemas.MustImport(&version, Backup{}, &backupHandler{})
+	server := httptest.NewServer(NewAPIServer(schemas))
+	defer server.Close()
+
+	conn := dialWebSocket(t, server, "/apis/testing/v1/backups/b1?action=run")
+	defer conn.Close()
+	ut.Assert(t, conn.WriteJSON(BackupInput{Target: "/tmp"}) == nil, "")
+	var events []resource.WatchEvent
+	for {
+		var e resource.WatchEvent
+		if err := conn.ReadJSON(&e); err != nil {
+			break
+		}
+		events = append(events, e)
+	}
+	ut.Equal(t, len(events), 3)
+	ut.Equal(t, events[0].Type, resource.InProgress)
+	ut.Equal(t, *events[0].Progress, resource.Progress{Percent: 50, Message: "copying"})
+	ut.Equal(t, events[2].Type, resource.Finished)
+	ut.Equal(t, events[2].Result, "/tmp")
+
+	conn2 := dialWebSocket(t, server, "/apis/testing/v1/backups/b1?action=run")
+	defer conn2.Close()
+	ut.Assert(t, conn2.WriteJSON(BackupInput{}) == nil, "")
+	var e resource.WatchEvent
+	ut.Assert(t, conn2.ReadJSON(&e) == nil, "")
+	ut.Equal(t, e.Type, resource.Failed)
+	ut.Equal(t, e.Error.Code, goresterr.InvalidAction.Code)
+}
+
+func TestShutdownClosesStreams(t *testing.T) {
+	oldInterval := WatchHeartbeatInterval
+	WatchHeartbeatInterval = 10 * time.Millisecond
+	defer func() { WatchHeartbeatInterval = oldInterval }()
+
+	schemas := schema.NewSchemaManager()
+	handler := &bazWatchHandler{watched: make(chan struct{})}
+	schemas.MustImport(&version, Baz{}, handler)
+	s := NewAPIServer(schemas)
+	server := httptest.NewServer(s)
+	defer server.Close()
+
+	conn := dialWebSocket(t, server, "/apis/testing/v1/bazs")
+	defer conn.Close()
+	pings := make(chan struct{}, 1)
+	conn.SetPingHandler(func(string) error {
+		select {
+		case pings <- struct{}{}:
+		default:
+		}
+		return nil
+	})
+	closed := make(chan error)
+	go func() {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				closed <- err
+				return
+			}
+		}
+	}()
+
+	select {
+	case <-pings:
+	case <-time.After(time.Second):
+		t.Fatal("no ping is received")
+	}
+
+	s.Shutdown()
+	select {
+	case err := <-closed:
+		ut.Assert(t, websocket.IsCloseError(err, websocket.CloseGoingAway), "unexpected close %v", err)
+	case <-time.After(time.Second):
+		t.Fatal("websocket isn't closed after shutdown")
+	}
+	<-handler.watched
+
+	req, _ := http.NewRequest("GET", "/apis/testing/v1/bazs?watch=true", nil)
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, goresterr.ServiceUnavailable.Status)
+}
+
+type Upgrade struct {
+	resource.ResourceBase `json:",inline"`
+}
+
+type UpgradeInput struct {
+	Version string `json:"version"`
+}
+
+func (u Upgrade) GetActions() []resource.Action {
+	return []resource.Action{
+		resource.Action{
+			Name:  "start",
+			Input: &UpgradeInput{},
+			Async: true,
+		},
+	}
+}
+
+type upgradeHandler struct {
+	progressed chan struct{}
+	release    chan struct{}
+	canceled   chan struct{}
+}
+
+func (h *upgradeHandler) Get(ctx *resource.Context) (resource.Resource, *goresterr.APIError) {
+	return nil, nil
+}
+
+func (h *upgradeHandler) Action(ctx *resource.Context) (interface{}, *goresterr.APIError) {
+	input := ctx.Resource.GetAction().Input.(*UpgradeInput)
+	ctx.ReportProgress(30, "downloading")
+	h.progressed <- struct{}{}
+	select {
+	case <-h.release:
+		return input.Version, nil
+	case <-ctx.Request.Context().Done():
+		close(h.canceled)
+		return nil, goresterr.NewAPIError(goresterr.ServerError, "canceled")
+	}
+}
+
+func TestAsyncAction(t *testing.T) {
+	schemas := schema.NewSchemaManager()
+	handler := &upgradeHandler{
+		progressed: make(chan struct{}),
+		release:    make(chan struct{}),
+		canceled:   make(chan struct{}),
+	}
+	schemas.MustImport(&version, Upgrade{}, handler)
+	s := NewAPIServer(schemas)
+
+	startTask := func() (string, *resource.Task) {
+		req, _ := http.NewRequest("POST", "/apis/testing/v1/upgrades/u1?action=start", strings.NewReader(`{"version":"v2"}`))
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, req)
+		ut.Equal(t, w.Code, http.StatusAccepted)
+		var task resource.Task
+		ut.Assert(t, json.Unmarshal(w.Body.Bytes(), &task) == nil, "")
+		return w.Header().Get("Location"), &task
+	}
+	getTask := func(link string) (int, *resource.Task) {
+		req, _ := http.NewRequest("GET", link, nil)
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, req)
+		var task resource.Task
+		json.Unmarshal(w.Body.Bytes(), &task)
+		return w.Code, &task
+	}
+
+	link, task := startTask()
+	ut.Equal(t, link, "/apis/testing/v1/upgrades/u1/tasks/"+task.GetID())
+	ut.Equal(t, task.Action, "start")
+	ut.Equal(t, task.State, resource.TaskRunning)
+
+	<-handler.progressed
+	code, task := getTask(link)
+	ut.Equal(t, code, http.StatusOK)
+	ut.Equal(t, task.State, resource.TaskRunning)
+	ut.Equal(t, task.Progress, resource.Progress{Percent: 30, Message: "downloading"})
+
+	handler.release <- struct{}{}
+	for task.State == resource.TaskRunning {
+		time.Sleep(time.Millisecond)
+		_, task = getTask(link)
+	}
+	ut.Equal(t, task.State, resource.TaskSucceeded)
+	ut.Equal(t, task.Result, "v2")
+
+	code, _ = getTask("/apis/testing/v1/upgrades/u2/tasks/" + task.GetID())
+	ut.Equal(t, code, http.StatusNotFound)
+
+	req, _ := http.NewRequest("DELETE", link, nil)
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, http.StatusNoContent)
+	code, _ = getTask(link)
+	ut.Equal(t, code, http.StatusNotFound)
+
+	link, _ = startTask()
+	<-handler.progressed
+	req, _ = http.NewRequest("DELETE", link, nil)
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, http.StatusNoContent)
+	<-handler.canceled
+	_, task = getTask(link)
+	ut.Equal(t, task.State, resource.TaskCanceled)
+	ut.Assert(t, task.Error == nil, "")
+}
+
+type upgradePanicHandler struct{}
+
+func (h *upgradePanicHandler) Get(ctx *resource.Context) (resource.Resource, *goresterr.APIError) {
+	return nil, nil
+}
+
+func (h *upgradePanicHandler) Action(ctx *resource.Context) (interface{}, *goresterr.APIError) {
+	panic("upgrade failed")
+}
+
+func TestAsyncActionPanic(t *testing.T) {
+	schemas := schema.NewSchemaManager()
+	schemas.MustImport(&version, Upgrade{}, &upgradePanicHandler{})
+	s := NewAPIServer(schemas)
+	logger := &testLogger{}
+	s.SetLogger(logger)
+
+	req, _ := http.NewRequest("POST", "/apis/testing/v1/upgrades/u1?action=start", strings.NewReader(`{"version":"v2"}`))
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, http.StatusAccepted)
+	link := w.Header().Get("Location")
+
+	task := &resource.Task{State: resource.TaskRunning}
+	for task.State == resource.TaskRunning {
+		time.Sleep(time.Millisecond)
+		req, _ := http.NewRequest("GET", link, nil)
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, req)
+		ut.Assert(t, json.Unmarshal(w.Body.Bytes(), task) == nil, "")
+	}
+	ut.Equal(t, task.State, resource.TaskFailed)
+	ut.Equal(t, task.Error.ErrorCode, goresterr.ServerError)
+	ut.Equal(t, len(logger.logs), 1)
+	ut.Assert(t, strings.Contains(logger.logs[0], "upgrade failed"), "")
+}
+
+func TestCollectionAction(t *testing.T) {
+	schemas := schema.NewSchemaManager()
+	schemas.MustImport(&version, Backup{}, &backupHandler{})
+	s := NewAPIServer(schemas)
+
+	route := schemas.GenerateResourceRoute()
+	ut.Equal(t, route[http.MethodPost], []string{
+		"/apis/testing/v1/backups/:backup_id",
+		"/apis/testing/v1/backups",
+	})
+
+	req, _ := http.NewRequest("POST", "/apis/testing/v1/backups?action=prune", nil)
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, http.StatusOK)
+	ut.Equal(t, w.Body.String(), `"prune"`)
+
+	//resource action posted to collection is still accepted
+	req, _ = http.NewRequest("POST", "/apis/testing/v1/backups?action=run", strings.NewReader(`{"target":"/tmp"}`))
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, http.StatusOK)
+	ut.Equal(t, w.Body.String(), `"run"`)
+
+	req, _ = http.NewRequest("POST", "/apis/testing/v1/backups/b1?action=prune", nil)
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, http.StatusNotFound)
+}
+
+type Login struct {
+	resource.ResourceBase `json:",inline"`
+}
+
+type LoginResult struct {
+	Token string `json:"token"`
+}
+
+func (l Login) GetActions() []resource.Action {
+	return []resource.Action{
+		resource.Action{
+			Name:   "good",
+			Output: &LoginResult{},
+		},
+		resource.Action{
+			Name:   "bad",
+			Output: &LoginResult{},
+		},
+	}
+}
+
+type loginHandler struct{}
+
+func (h *loginHandler) Action(ctx *resource.Context) (interface{}, *goresterr.APIError) {
+	if ctx.Resource.GetAction().Name == "good" {
+		return &LoginResult{Token: "t"}, nil
+	}
+	return "t", nil
+}
+
+func TestActionOutputCheck(t *testing.T) {
+	schemas := schema.NewSchemaManager()
+	schemas.MustImport(&version, Login{}, &loginHandler{})
+	s := NewAPIServer(schemas)
+
+	cases := []struct {
+		debug  bool
+		action string
+		code   int
+	}{
+		{false, "good", http.StatusOK},
+		{false, "bad", http.StatusOK},
+		{true, "good", http.StatusOK},
+		{true, "bad", goresterr.ServerError.Status},
+	}
+	for _, tc := range cases {
+		s.SetDebug(tc.debug)
+		req, _ := http.NewRequest("POST", "/apis/testing/v1/logins/l1?action="+tc.action, nil)
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, req)
+		ut.Equal(t, w.Code, tc.code)
+	}
+}
+
+type Dnsconfig struct {
+	resource.ResourceBase `json:",inline"`
+	Forwarders            []string `json:"forwarders"`
+}
+
+func (d Dnsconfig) GetParents() []resource.ResourceKind {
+	return []resource.ResourceKind{Tree{}}
+}
+
+func (d Dnsconfig) IsSingleton() bool {
+	return true
+}
+
+type dnsconfigHandler struct {
+	config *Dnsconfig
+}
+
+func (h *dnsconfigHandler) Get(ctx *resource.Context) (resource.Resource, *goresterr.APIError) {
+	return h.config, nil
+}
+
+func (h *dnsconfigHandler) Update(ctx *resource.Context) (resource.Resource, *goresterr.APIError) {
+	h.config = ctx.Resource.(*Dnsconfig)
+	return h.config, nil
+}
+
+func (h *dnsconfigHandler) Delete(ctx *resource.Context) *goresterr.APIError {
+	return nil
+}
+
+func TestSingleton(t *testing.T) {
+	schemas := schema.NewSchemaManager()
+	schemas.MustImport(&version, Tree{}, &treeHandler{})
+	handler := &dnsconfigHandler{config: &Dnsconfig{Forwarders: []string{"8.8.8.8"}}}
+	schemas.MustImport(&version, Dnsconfig{}, handler)
+	s := NewAPIServer(schemas)
+
+	route := schemas.GenerateResourceRoute()
+	ut.Equal(t, route[http.MethodPut], []string{"/apis/testing/v1/trees/:tree_id/dnsconfig"})
+	ut.Equal(t, len(route[http.MethodDelete]), 0)
+
+	req, _ := http.NewRequest("GET", "/apis/testing/v1/trees/t1/dnsconfig", nil)
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, http.StatusOK)
+	var config map[string]interface{}
+	ut.Assert(t, json.Unmarshal(w.Body.Bytes(), &config) == nil, "")
+	ut.Equal(t, config["links"], map[string]interface{}{
+		"self":   "/apis/testing/v1/trees/t1/dnsconfig",
+		"update": "/apis/testing/v1/trees/t1/dnsconfig",
+	})
+
+	req, _ = http.NewRequest("PUT", "/apis/testing/v1/trees/t1/dnsconfig", strings.NewReader(`{"forwarders":["1.1.1.1"]}`))
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, http.StatusOK)
+	ut.Equal(t, handler.config.Forwarders, []string{"1.1.1.1"})
+
+	for _, method := range []string{http.MethodDelete, http.MethodPost} {
+		req, _ = http.NewRequest(method, "/apis/testing/v1/trees/t1/dnsconfig", nil)
+		w = httptest.NewRecorder()
+		s.ServeHTTP(w, req)
+		ut.Equal(t, w.Code, goresterr.MethodNotAllowed.Status)
+	}
+
+	req, _ = http.NewRequest("GET", "/apis/testing/v1/trees/t1/dnsconfig/d1", nil)
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, http.StatusNotFound)
+
+	h, _ := resource.HandlerAdaptor(handler)
+	doc, err := resourcedoc.NewResourceDocument("dnsconfig", Dnsconfig{}, h, []string{"tree"})
+	ut.Assert(t, err == nil, "")
+	ut.Assert(t, doc.Singleton, "")
+	ut.Equal(t, doc.CollectionName, "")
+	ut.Equal(t, doc.ResourceMethods, []resource.HttpMethod{http.MethodGet, http.MethodPut})
+	ut.Equal(t, len(doc.CollectionMethods), 0)
+}
+
+type Ntpconfig struct {
+	resource.ResourceBase `json:",inline"`
+	Servers               []string `json:"servers"`
+}
+
+func (n Ntpconfig) GetParents() []resource.ResourceKind {
+	return []resource.ResourceKind{Tree{}}
+}
+
+func (n Ntpconfig) IsSingleton() bool {
+	return true
+}
+
+func (n Ntpconfig) GetKindName() string {
+	return ""
+}
+
+func (n Ntpconfig) GetResourceName() string {
+	return "ntp"
+}
+
+type ntpconfigHandler struct{}
+
+func (h *ntpconfigHandler) Get(ctx *resource.Context) (resource.Resource, *goresterr.APIError) {
+	return &Ntpconfig{Servers: []string{"pool.ntp.org"}}, nil
+}
+
+func TestSingletonWithResourceName(t *testing.T) {
+	schemas := schema.NewSchemaManager()
+	schemas.MustImport(&version, Tree{}, &treeHandler{})
+	schemas.MustImport(&version, Ntpconfig{}, &ntpconfigHandler{})
+	s := NewAPIServer(schemas)
+
+	route := schemas.GenerateResourceRoute()
+	ut.Equal(t, route[http.MethodGet], []string{"/apis/testing/v1/trees/:tree_id", "/apis/testing/v1/trees/:tree_id/ntp"})
+
+	req, _ := http.NewRequest("GET", "/apis/testing/v1/trees/t1/ntp", nil)
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, http.StatusOK)
+	var config map[string]interface{}
+	ut.Assert(t, json.Unmarshal(w.Body.Bytes(), &config) == nil, "")
+	ut.Equal(t, config["type"], "ntpconfig")
+	ut.Equal(t, config["links"], map[string]interface{}{
+		"self": "/apis/testing/v1/trees/t1/ntp",
+	})
+
+	req, _ = http.NewRequest("GET", "/apis/testing/v1/trees/t1/ntpconfig", nil)
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, http.StatusNotFound)
+}
+
+type Person struct {
+	resource.ResourceBase `json:",inline"`
+	Name                  string `json:"name"`
+}
+
+func (p Person) GetKindName() string {
+	return "human"
+}
+
+func (p Person) GetResourceName() string {
+	return "people"
+}
+
+type personHandler struct{}
+
+func (h *personHandler) Get(ctx *resource.Context) (resource.Resource, *goresterr.APIError) {
+	p := &Person{Name: "p1"}
+	p.SetID(ctx.Resource.GetID())
+	return p, nil
+}
+
+func (h *personHandler) List(ctx *resource.Context) (interface{}, *goresterr.APIError) {
+	p := &Person{Name: "p1"}
+	p.SetID("p1")
+	return []*Person{p}, nil
+}
+
+func TestCustomResourceName(t *testing.T) {
+	schemas := schema.NewSchemaManager()
+	schemas.MustImport(&version, Person{}, &personHandler{})
+	s := NewAPIServer(schemas)
+
+	route := schemas.GenerateResourceRoute()
+	ut.Equal(t, route[http.MethodGet], []string{
+		"/apis/testing/v1/people/:human_id",
+		"/apis/testing/v1/people",
+	})
+
+	req, _ := http.NewRequest("GET", "/apis/testing/v1/people", nil)
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, http.StatusOK)
+	var rc struct {
+		ResourceType string `json:"resourceType"`
+		Data         []struct {
+			Type  string            `json:"type"`
+			Links map[string]string `json:"links"`
+		} `json:"data"`
+	}
+	ut.Assert(t, json.Unmarshal(w.Body.Bytes(), &rc) == nil, "")
+	ut.Equal(t, rc.ResourceType, "human")
+	ut.Equal(t, rc.Data[0].Type, "human")
+	ut.Equal(t, rc.Data[0].Links["self"], "/apis/testing/v1/people/p1")
+
+	h, _ := resource.HandlerAdaptor(&personHandler{})
+	doc, err := resourcedoc.NewResourceDocument("human", Person{}, h, nil)
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, doc.CollectionName, "people")
+}
+
+type Deployment struct {
+	resource.ResourceBase `json:",inline"`
+	Replicas              int    `json:"replicas" rest:"required=true,min=1,max=10"`
+	ReadyReplicas         int    `json:"readyReplicas" rest:"status,min=0,max=10"`
+	Phase                 string `json:"phase" rest:"status,options=pending|running"`
+}
+
+func (d Deployment) SupportStatus() bool {
+	return true
+}
+
+type deploymentHandler struct {
+	deployment Deployment
+	updated    Deployment
+}
+
+func (h *deploymentHandler) Get(ctx *resource.Context) (resource.Resource, *goresterr.APIError) {
+	d := h.deployment
+	return &d, nil
+}
+
+func (h *deploymentHandler) Update(ctx *resource.Context) (resource.Resource, *goresterr.APIError) {
+	h.updated = *ctx.Resource.(*Deployment)
+	h.deployment.Replicas = h.updated.Replicas
+	d := h.deployment
+	return &d, nil
+}
+
+func (h *deploymentHandler) Patch(ctx *resource.Context) (resource.Resource, *goresterr.APIError) {
+	return h.Update(ctx)
+}
+
+func (h *deploymentHandler) UpdateStatus(ctx *resource.Context) (resource.Resource, *goresterr.APIError) {
+	status := ctx.Resource.(*Deployment)
+	h.deployment.ReadyReplicas = status.ReadyReplicas
+	h.deployment.Phase = status.Phase
+	d := h.deployment
+	return &d, nil
+}
+
+func TestStatusSubresource(t *testing.T) {
+	schemas := schema.NewSchemaManager()
+	handler := &deploymentHandler{}
+	handler.deployment.SetID("d1")
+	schemas.MustImport(&version, Deployment{}, handler)
+	s := NewAPIServer(schemas)
+
+	route := schemas.GenerateResourceRoute()
+	ut.Equal(t, route[http.MethodPut], []string{
+		"/apis/testing/v1/deployments/:deployment_id",
+		"/apis/testing/v1/deployments/:deployment_id/status",
+	})
+
+	//status in update is ignored and not validated
+	req, _ := http.NewRequest("PUT", "/apis/testing/v1/deployments/d1", strings.NewReader(`{"replicas":3,"readyReplicas":100,"phase":"unknown"}`))
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, http.StatusOK)
+	ut.Equal(t, handler.deployment.Replicas, 3)
+	ut.Equal(t, handler.deployment.ReadyReplicas, 0)
+	ut.Equal(t, handler.deployment.Phase, "")
+	var deployment map[string]interface{}
+	ut.Assert(t, json.Unmarshal(w.Body.Bytes(), &deployment) == nil, "")
+	ut.Equal(t, deployment["links"], map[string]interface{}{
+		"self":   "/apis/testing/v1/deployments/d1",
+		"update": "/apis/testing/v1/deployments/d1",
+		"patch":  "/apis/testing/v1/deployments/d1",
+		"status": "/apis/testing/v1/deployments/d1/status",
+	})
+
+	//spec in status update is ignored and required spec isn't checked
+	req, _ = http.NewRequest("PUT", "/apis/testing/v1/deployments/d1/status", strings.NewReader(`{"replicas":5,"readyReplicas":2,"phase":"running"}`))
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, http.StatusOK)
+	ut.Equal(t, handler.deployment.Replicas, 3)
+	ut.Equal(t, handler.deployment.ReadyReplicas, 2)
+	ut.Equal(t, handler.deployment.Phase, "running")
+
+	for _, body := range []string{`{"readyReplicas":11}`, `{"phase":"unknown"}`} {
+		req, _ = http.NewRequest("PUT", "/apis/testing/v1/deployments/d1/status", strings.NewReader(body))
+		w = httptest.NewRecorder()
+		s.ServeHTTP(w, req)
+		ut.Equal(t, w.Code, goresterr.InvalidBodyContent.Status)
+	}
+
+	req, _ = http.NewRequest("PUT", "/apis/testing/v1/deployments/d1", strings.NewReader(`{"readyReplicas":2}`))
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, goresterr.InvalidBodyContent.Status)
+
+	//update handler gets the stored status, status in patch is dropped
+	for _, method := range []string{"PUT", "PATCH"} {
+		req, _ = http.NewRequest(method, "/apis/testing/v1/deployments/d1", strings.NewReader(`{"replicas":4,"phase":"pending"}`))
+		w = httptest.NewRecorder()
+		s.ServeHTTP(w, req)
+		ut.Equal(t, w.Code, http.StatusOK)
+		ut.Equal(t, handler.updated.Replicas, 4)
+		ut.Equal(t, handler.updated.ReadyReplicas, 2)
+		ut.Equal(t, handler.updated.Phase, "running")
+		ut.Equal(t, handler.deployment.Phase, "running")
+	}
+
+	req, _ = http.NewRequest("PATCH", "/apis/testing/v1/deployments/d1/status", strings.NewReader(`{"phase":"running"}`))
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, goresterr.MethodNotAllowed.Status)
+
+	h, _ := resource.HandlerAdaptor(handler)
+	doc, err := resourcedoc.NewResourceDocument("deployment", Deployment{}, h, nil)
+	ut.Assert(t, err == nil, "")
+	ut.Assert(t, doc.SupportStatus, "")
+	ut.Equal(t, doc.ResourceFields["phase"].Description, []string{"status"})
+}
+
+type Account struct {
+	resource.ResourceBase `json:",inline"`
+	Name                  string       `json:"name" rest:"required=true,immutable"`
+	Password              string       `json:"password,omitempty" rest:"writeonly"`
+	LoginCount            int          `json:"loginCount" rest:"readonly"`
+	Email                 string       `json:"email"`
+	Keys                  []AccountKey `json:"keys"`
+}
+
+type AccountKey struct {
+	Name   string `json:"name"`
+	Secret string `json:"secret" rest:"writeonly"`
+}
+
+type accountHandler struct {
+	account Account
+}
+
+func (h *accountHandler) Create(ctx *resource.Context) (resource.Resource, *goresterr.APIError) {
+	h.account = *ctx.Resource.(*Account)
+	h.account.SetID(h.account.Name)
+	h.account.LoginCount = 1
+	a := h.account
+	return &a, nil
+}
+
+func (h *accountHandler) Get(ctx *resource.Context) (resource.Resource, *goresterr.APIError) {
+	a := h.account
+	return &a, nil
+}
+
+func (h *accountHandler) List(ctx *resource.Context) (interface{}, *goresterr.APIError) {
+	a := h.account
+	return []*Account{&a}, nil
+}
+
+func (h *accountHandler) Update(ctx *resource.Context) (resource.Resource, *goresterr.APIError) {
+	account := ctx.Resource.(*Account)
+	h.account.Email = account.Email
+	h.account.Password = account.Password
+	a := h.account
+	return &a, nil
+}
+
+func (h *accountHandler) Patch(ctx *resource.Context) (resource.Resource, *goresterr.APIError) {
+	return h.Update(ctx)
+}
+
+//update without get handler can't check immutable fields
+type accountUpdater struct{}
+
+func (h *accountUpdater) Update(ctx *resource.Context) (resource.Resource, *goresterr.APIError) {
+	return ctx.Resource, nil
+}
+
+func TestFieldAccessTags(t *testing.T) {
+	schemas := schema.NewSchemaManager()
+	handler := &accountHandler{}
+	schemas.MustImport(&version, Account{}, handler)
+	s := NewAPIServer(schemas)
+
+	serve := func(method, url, contentType, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, url, strings.NewReader(body))
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, req)
+		return w
+	}
+
+	//readonly field is ignored, and writeonly field isn't returned
+	w := serve("POST", "/apis/testing/v1/accounts", "", `{"name":"ben","password":"secret","loginCount":100,"keys":[{"name":"ssh","secret":"keysecret"}]}`)
+	ut.Equal(t, w.Code, http.StatusCreated)
+	ut.Equal(t, handler.account.Password, "secret")
+	ut.Equal(t, handler.account.Keys[0].Secret, "keysecret")
+	ut.Assert(t, strings.Contains(w.Body.String(), "ssh"), "")
+	ut.Equal(t, handler.account.LoginCount, 1)
+	ut.Assert(t, strings.Contains(w.Body.String(), "secret") == false, "password is returned:%s", w.Body.String())
+
+	for _, url := range []string{"/apis/testing/v1/accounts", "/apis/testing/v1/accounts/ben", "/apis/testing/v1/accounts/ben?fields=name,password"} {
+		w = serve("GET", url, "", "")
+		ut.Equal(t, w.Code, http.StatusOK)
+		ut.Assert(t, strings.Contains(w.Body.String(), "ben"), "")
+		ut.Assert(t, strings.Contains(w.Body.String(), "secret") == false, "password is returned:%s", w.Body.String())
+	}
+
+	//writeonly field of nested struct is hidden in watch event as well
+	r, _ := resource.HideWriteOnlyFields(&handler.account)
+	data, _ := json.Marshal(r)
+	ut.Equal(t, strings.Contains(string(data), "keysecret"), false)
+
+	w = serve("PUT", "/apis/testing/v1/accounts/ben", "", `{"name":"ben","email":"ben@zdns.cn","loginCount":100}`)
+	ut.Equal(t, w.Code, http.StatusOK)
+	ut.Equal(t, handler.account.Email, "ben@zdns.cn")
+	ut.Equal(t, handler.account.LoginCount, 1)
+
+	w = serve("PUT", "/apis/testing/v1/accounts/ben", "", `{"name":"bob"}`)
+	ut.Equal(t, w.Code, goresterr.InvalidBodyContent.Status)
+	ut.Equal(t, handler.account.Name, "ben")
+
+	w = serve("PATCH", "/apis/testing/v1/accounts/ben", "", `{"name":"bob"}`)
+	ut.Equal(t, w.Code, goresterr.InvalidBodyContent.Status)
+
+	w = serve("PATCH", "/apis/testing/v1/accounts/ben", "application/json-patch+json",
+		`[{"op":"replace","path":"/loginCount","value":100},{"op":"replace","path":"/email","value":"ben@gmail.com"}]`)
+	ut.Equal(t, w.Code, http.StatusOK)
+	ut.Equal(t, handler.account.Email, "ben@gmail.com")
+	ut.Equal(t, handler.account.LoginCount, 1)
+
+	h, _ := resource.HandlerAdaptor(handler)
+	doc, err := resourcedoc.NewResourceDocument("account", Account{}, h, nil)
+	ut.Assert(t, err == nil, "")
+	ut.Assert(t, doc.ResourceFields["name"].Immutable, "")
+	ut.Assert(t, doc.ResourceFields["password"].WriteOnly, "")
+	ut.Assert(t, doc.ResourceFields["loginCount"].ReadOnly, "")
+
+	ut.Assert(t, schema.NewSchemaManager().Import(&version, Account{}, &accountUpdater{}) != nil, "")
+}
+
+type evenValidator struct{}
+
+func (v *evenValidator) Validate(val interface{}) error {
+	if val.(int)%2 != 0 {
+		return fmt.Errorf("%d isn't even", val)
+	}
+	return nil
+}
+
+type evenValidatorBuilder struct{}
+
+func (b *evenValidatorBuilder) FromTags(tags []string) (validator.Validator, error) {
+	for _, tag := range tags {
+		if tag == "isEven" {
+			return &evenValidator{}, nil
+		}
+	}
+	return nil, nil
+}
+
+func (b *evenValidatorBuilder) SupportKind(kind util.Kind) bool {
+	return kind == util.Int
+}
+
+func (b *evenValidatorBuilder) TagNames() []string {
+	return []string{"isEven"}
+}
+
+//custom validator can't be unregistered, register it once for all the tests
+func init() {
+	if err := validator.Register(&evenValidatorBuilder{}); err != nil {
+		panic(err)
+	}
+}
+
+type Lease struct {
+	resource.ResourceBase `json:",inline"`
+	Start                 int `json:"start" rest:"isEven"`
+	End                   int `json:"end"`
+}
+
+func (l *Lease) Validate(ctx *resource.Context) error {
+	if l.Start >= l.End {
+		return goresterr.NewAPIError(goresterr.InvalidFormat, "start should be less than end")
+	}
+	return nil
+}
+
+type leaseHandler struct{}
+
+func (h *leaseHandler) Create(ctx *resource.Context) (resource.Resource, *goresterr.APIError) {
+	ctx.Resource.SetID("l1")
+	return ctx.Resource, nil
+}
+
+func TestCustomValidation(t *testing.T) {
+	schemas := schema.NewSchemaManager()
+	schemas.MustImport(&version, Lease{}, &leaseHandler{})
+	s := NewAPIServer(schemas)
+
+	req, _ := http.NewRequest("POST", "/apis/testing/v1/leases", strings.NewReader(`{"start":2,"end":10}`))
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	ut.Equal(t, w.Code, http.StatusCreated)
+
+	//field validator and kind level validation report different errors
+	cases := []struct {
+		body string
+		code goresterr.ErrorCode
+	}{
+		{`{"start":3,"end":10}`, goresterr.InvalidBodyContent},
+		{`{"start":10,"end":2}`, goresterr.InvalidFormat},
+	}
+	for _, tc := range cases {
+		req, _ := http.NewRequest("POST", "/apis/testing/v1/leases", strings.NewReader(tc.body))
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, req)
+		var apiErr goresterr.APIError
+		ut.Assert(t, json.Unmarshal(w.Body.Bytes(), &apiErr) == nil, "")
+		ut.Equal(t, apiErr.ErrorCode, tc.code)
+	}
+
+	h, _ := resource.HandlerAdaptor(&leaseHandler{})
+	doc, err := resourcedoc.NewResourceDocument("lease", Lease{}, h, nil)
+	ut.Assert(t, err == nil, "")
+	ut.Assert(t, doc.CustomValidation, "")
+	ut.Equal(t, doc.ResourceFields["start"].Description, []string{"isEven"})
+}
+
+type Pool struct {
+	resource.ResourceBase `json:",inline"`
+	StartIP               string `json:"startIP" rest:"required=true,isIP=true"`
+	EndIP                 string `json:"endIP" rest:"required=true,isIP=true,gteField=startIP"`
+	Protocol              string `json:"protocol" rest:"options=http|https"`
+	TLSCert               string `json:"tlsCert" rest:"requiredIf=protocol:https"`
+	Mode                  string `json:"mode"`
+	Replicas              int    `json:"replicas" rest:"requiredIf=mode:ha"`
+}
+
+type poolHandler struct {
+	pool *Pool
+}
+
+func (h *poolHandler) Create(ctx *resource.Context) (resource.Resource, *goresterr.APIError) {
+	ctx.Resource.SetID("p1")
+	return ctx.Resource, nil
+}
+
+func (h *poolHandler) Get(ctx *resource.Context) (resource.Resource, *goresterr.APIError) {
+	return h.pool, nil
+}
+
+func (h *poolHandler) Patch(ctx *resource.Context) (resource.Resource, *goresterr.APIError) {
+	h.pool = ctx.Resource.(*Pool)
+	return h.pool, nil
+}
+
+func TestFieldRules(t *testing.T) {
+	schemas := schema.NewSchemaManager()
+	pool := &Pool{StartIP: "10.0.0.1", EndIP: "10.0.0.9", Protocol: "http"}
+	pool.SetID("p1")
+	handler := &poolHandler{pool: pool}
+	schemas.MustImport(&version, Pool{}, handler)
+	s := NewAPIServer(schemas)
+
+	cases := []struct {
+		method string
+		body   string
+		code   int
+	}{
+		{"POST", `{"startIP":"10.0.0.2","endIP":"10.0.0.10"}`, http.StatusCreated},
+		{"POST", `{"startIP":"10.0.0.10","endIP":"10.0.0.2"}`, goresterr.InvalidBodyContent.Status},
+		{"POST", `{"startIP":"10.0.0.2","endIP":"10.0.0.10","protocol":"https"}`, goresterr.InvalidBodyContent.Status},
+		{"POST", `{"startIP":"10.0.0.2","endIP":"10.0.0.10","mode":"ha","replicas":0}`, http.StatusCreated},
+		{"POST", `{"startIP":"10.0.0.2","endIP":"10.0.0.10","mode":"ha"}`, goresterr.InvalidBodyContent.Status},
+		{"PATCH", `{"protocol":"https"}`, goresterr.InvalidBodyContent.Status},
+		{"PATCH", `{"endIP":"10.0.0.0"}`, goresterr.InvalidBodyContent.Status},
+		{"PATCH", `{"protocol":"https","tlsCert":"cert"}`, http.StatusOK},
+	}
+	for _, tc := range cases {
+		url := "/apis/testing/v1/pools"
+		if tc.method == "PATCH" {
+			url += "/p1"
+		}
+		req, _ := http.NewRequest(tc.method, url, strings.NewReader(tc.body))
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, req)
+		ut.Equal(t, w.Code, tc.code)
+	}
+	ut.Equal(t, handler.pool.TLSCert, "cert")
+
+	h, _ := resource.HandlerAdaptor(&poolHandler{})
+	doc, err := resourcedoc.NewResourceDocument("pool", Pool{}, h, nil)
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, doc.ResourceFields["tlsCert"].Description, []string{"requiredIf=protocol:https"})
+}
@@ -0,0 +1,334 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	PatchOpAdd     = "add"
+	PatchOpRemove  = "remove"
+	PatchOpReplace = "replace"
+	PatchOpMove    = "move"
+	PatchOpCopy    = "copy"
+	PatchOpTest    = "test"
+)
+
+//one operation of json patch(rfc 6902)
+type JSONPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+func ParseJSONPatch(data []byte) ([]JSONPatchOperation, error) {
+	var ops []JSONPatchOperation
+	if err := json.Unmarshal(data, &ops); err != nil {
+		return nil, fmt.Errorf("json patch isn't an array of operations:%s", err.Error())
+	}
+
+	for _, op := range ops {
+		if _, err := ParseJSONPointer(op.Path); err != nil {
+			return nil, err
+		}
+
+		switch op.Op {
+		case PatchOpAdd, PatchOpReplace, PatchOpTest:
+			if len(op.Value) == 0 {
+				return nil, fmt.Errorf("%s operation on %s has no value", op.Op, op.Path)
+			}
+		case PatchOpMove, PatchOpCopy:
+			if _, err := ParseJSONPointer(op.From); err != nil {
+				return nil, err
+			}
+		case PatchOpRemove:
+		default:
+			return nil, fmt.Errorf("unknown json patch operation %s", op.Op)
+		}
+	}
+	return ops, nil
+}
+
+//split json pointer(rfc 6901) into unescaped reference tokens
+//empty pointer refers to the whole document
+func ParseJSONPointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+
+	if strings.HasPrefix(pointer, "/") == false {
+		return nil, fmt.Errorf("json pointer %s doesn't start with /", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.Replace(strings.Replace(token, "~1", "/", -1), "~0", "~", -1)
+	}
+	return tokens, nil
+}
+
+//join reference tokens into json pointer, it's the reverse of ParseJSONPointer
+func JSONPointer(tokens ...string) string {
+	var pointer strings.Builder
+	for _, token := range tokens {
+		pointer.WriteString("/")
+		pointer.WriteString(strings.Replace(strings.Replace(token, "~", "~0", -1), "/", "~1", -1))
+	}
+	return pointer.String()
+}
+
+func ApplyJSONPatch(doc []byte, ops []JSONPatchOperation) ([]byte, error) {
+	root, err := decodeJSON(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, op := range ops {
+		if root, err = applyJSONPatchOperation(root, op); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(root)
+}
+
+func applyJSONPatchOperation(root interface{}, op JSONPatchOperation) (interface{}, error) {
+	path, err := ParseJSONPointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	var value interface{}
+	if len(op.Value) != 0 {
+		if value, err = decodeJSON(op.Value); err != nil {
+			return nil, err
+		}
+	}
+
+	switch op.Op {
+	case PatchOpAdd:
+		return addValue(root, path, value)
+	case PatchOpRemove:
+		return removeValue(root, path)
+	case PatchOpReplace:
+		if _, err := getValue(root, path); err != nil {
+			return nil, err
+		}
+		if root, err = removeValue(root, path); err != nil {
+			return nil, err
+		}
+		return addValue(root, path, value)
+	case PatchOpMove:
+		from, _ := ParseJSONPointer(op.From)
+		if op.From != op.Path && strings.HasPrefix(op.Path, op.From+"/") {
+			return nil, fmt.Errorf("can't move %s to its child %s", op.From, op.Path)
+		}
+		if value, err = getValue(root, from); err != nil {
+			return nil, err
+		}
+		if root, err = removeValue(root, from); err != nil {
+			return nil, err
+		}
+		return addValue(root, path, value)
+	case PatchOpCopy:
+		from, _ := ParseJSONPointer(op.From)
+		if value, err = getValue(root, from); err != nil {
+			return nil, err
+		}
+		if value, err = deepCopyJSON(value); err != nil {
+			return nil, err
+		}
+		return addValue(root, path, value)
+	case PatchOpTest:
+		current, err := getValue(root, path)
+		if err != nil {
+			return nil, err
+		}
+		if reflect.DeepEqual(current, value) == false {
+			return nil, fmt.Errorf("test operation on %s failed", op.Path)
+		}
+		return root, nil
+	default:
+		return nil, fmt.Errorf("unknown json patch operation %s", op.Op)
+	}
+}
+
+func getValue(root interface{}, path []string) (interface{}, error) {
+	node := root
+	for _, token := range path {
+		child, err := getChild(node, token)
+		if err != nil {
+			return nil, err
+		}
+		node = child
+	}
+	return node, nil
+}
+
+func addValue(root interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	return updateParent(root, path, func(parent interface{}, token string) (interface{}, error) {
+		switch p := parent.(type) {
+		case map[string]interface{}:
+			p[token] = value
+			return p, nil
+		case []interface{}:
+			if token == "-" {
+				return append(p, value), nil
+			}
+			i, err := arrayIndex(token, len(p)+1)
+			if err != nil {
+				return nil, err
+			}
+			p = append(p, nil)
+			copy(p[i+1:], p[i:])
+			p[i] = value
+			return p, nil
+		default:
+			return nil, fmt.Errorf("can't add %s to non-container value", token)
+		}
+	})
+}
+
+func removeValue(root interface{}, path []string) (interface{}, error) {
+	if len(path) == 0 {
+		return nil, fmt.Errorf("can't remove the whole document")
+	}
+
+	return updateParent(root, path, func(parent interface{}, token string) (interface{}, error) {
+		switch p := parent.(type) {
+		case map[string]interface{}:
+			if _, ok := p[token]; ok == false {
+				return nil, fmt.Errorf("member %s doesn't exist", token)
+			}
+			delete(p, token)
+			return p, nil
+		case []interface{}:
+			i, err := arrayIndex(token, len(p))
+			if err != nil {
+				return nil, err
+			}
+			return append(p[:i], p[i+1:]...), nil
+		default:
+			return nil, fmt.Errorf("can't remove %s from non-container value", token)
+		}
+	})
+}
+
+//find the parent of the last token and replace it with the value returned by fn
+func updateParent(node interface{}, path []string, fn func(interface{}, string) (interface{}, error)) (interface{}, error) {
+	if len(path) == 1 {
+		return fn(node, path[0])
+	}
+
+	child, err := getChild(node, path[0])
+	if err != nil {
+		return nil, err
+	}
+
+	newChild, err := updateParent(child, path[1:], fn)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n := node.(type) {
+	case map[string]interface{}:
+		n[path[0]] = newChild
+	case []interface{}:
+		i, _ := arrayIndex(path[0], len(n))
+		n[i] = newChild
+	}
+	return node, nil
+}
+
+func getChild(node interface{}, token string) (interface{}, error) {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		if child, ok := n[token]; ok {
+			return child, nil
+		}
+		return nil, fmt.Errorf("member %s doesn't exist", token)
+	case []interface{}:
+		i, err := arrayIndex(token, len(n))
+		if err != nil {
+			return nil, err
+		}
+		return n[i], nil
+	default:
+		return nil, fmt.Errorf("can't get %s from non-container value", token)
+	}
+}
+
+//index should in range [0, max)
+func arrayIndex(token string, max int) (int, error) {
+	i, err := strconv.Atoi(token)
+	if err != nil || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("%s isn't valid array index", token)
+	}
+
+	if i < 0 || i >= max {
+		return 0, fmt.Errorf("array index %d out of range", i)
+	}
+	return i, nil
+}
+
+//merge patch(rfc 7386)
+func ApplyMergePatch(doc, patch []byte) ([]byte, error) {
+	target, err := decodeJSON(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := decodeJSON(patch)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(mergePatch(target, p))
+}
+
+func mergePatch(target, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if ok == false {
+		return patch
+	}
+
+	t, ok := target.(map[string]interface{})
+	if ok == false {
+		t = make(map[string]interface{})
+	}
+
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+		} else {
+			t[k] = mergePatch(t[k], v)
+		}
+	}
+	return t
+}
+
+func decodeJSON(data []byte) (interface{}, error) {
+	var v interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&v); err != nil {
+		return nil, fmt.Errorf("invalid json:%s", err.Error())
+	}
+	return v, nil
+}
+
+func deepCopyJSON(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return decodeJSON(data)
+}
@@ -0,0 +1,108 @@
+package util
+
+import (
+	"encoding/json"
+	"testing"
+
+	ut "github.com/zdnscloud/cement/unittest"
+)
+
+func jsonEqual(t *testing.T, a, b string) {
+	var va, vb interface{}
+	ut.Assert(t, json.Unmarshal([]byte(a), &va) == nil, "invalid json %s", a)
+	ut.Assert(t, json.Unmarshal([]byte(b), &vb) == nil, "invalid json %s", b)
+	ut.Equal(t, va, vb)
+}
+
+func TestMergePatch(t *testing.T) {
+	cases := []struct {
+		doc    string
+		patch  string
+		result string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+
+	for _, tc := range cases {
+		result, err := ApplyMergePatch([]byte(tc.doc), []byte(tc.patch))
+		ut.Assert(t, err == nil, "merge patch failed:%v", err)
+		jsonEqual(t, string(result), tc.result)
+	}
+}
+
+func TestJSONPatch(t *testing.T) {
+	cases := []struct {
+		doc    string
+		patch  string
+		result string
+	}{
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"baz":"qux","foo":"bar"}`},
+		{`{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`},
+		{`{"foo":["bar"]}`, `[{"op":"add","path":"/foo/-","value":"qux"}]`, `{"foo":["bar","qux"]}`},
+		{`{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`},
+		{`{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`},
+		{`{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`},
+		{`{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`, `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`,
+			`{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+		{`{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, `{"foo":["all","cows","eat","grass"]}`},
+		{`{"foo":{"bar":1}}`, `[{"op":"copy","from":"/foo","path":"/baz"}]`, `{"foo":{"bar":1},"baz":{"bar":1}}`},
+		{`{"baz":"qux","foo":["a",2,"c"]}`, `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2}]`, `{"baz":"qux","foo":["a",2,"c"]}`},
+		{`{"a/b":1,"m~n":2}`, `[{"op":"replace","path":"/a~1b","value":3},{"op":"remove","path":"/m~0n"}]`, `{"a/b":3}`},
+	}
+
+	for _, tc := range cases {
+		ops, err := ParseJSONPatch([]byte(tc.patch))
+		ut.Assert(t, err == nil, "parse patch failed:%v", err)
+		result, err := ApplyJSONPatch([]byte(tc.doc), ops)
+		ut.Assert(t, err == nil, "json patch failed:%v", err)
+		jsonEqual(t, string(result), tc.result)
+	}
+
+	invalidCases := []struct {
+		doc   string
+		patch string
+	}{
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/baz/bat","value":"qux"}]`},
+		{`{"foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`},
+		{`{"foo":"bar"}`, `[{"op":"replace","path":"/baz","value":1}]`},
+		{`{"foo":["bar"]}`, `[{"op":"add","path":"/foo/2","value":"qux"}]`},
+		{`{"foo":["bar"]}`, `[{"op":"remove","path":"/foo/01"}]`},
+		{`{"baz":"qux"}`, `[{"op":"test","path":"/baz","value":"bar"}]`},
+		{`{"foo":{"bar":1}}`, `[{"op":"move","from":"/foo","path":"/foo/baz"}]`},
+	}
+	for _, tc := range invalidCases {
+		ops, err := ParseJSONPatch([]byte(tc.patch))
+		ut.Assert(t, err == nil, "parse patch failed:%v", err)
+		_, err = ApplyJSONPatch([]byte(tc.doc), ops)
+		ut.Assert(t, err != nil, "patch %s should fail", tc.patch)
+	}
+
+	invalidPatches := []string{
+		`{"op":"add","path":"/baz","value":"qux"}`,
+		`[{"op":"add","path":"/baz"}]`,
+		`[{"op":"unknown","path":"/baz"}]`,
+		`[{"op":"remove","path":"baz"}]`,
+		`[{"op":"copy","path":"/baz","from":"foo"}]`,
+	}
+	for _, patch := range invalidPatches {
+		_, err := ParseJSONPatch([]byte(patch))
+		ut.Assert(t, err != nil, "patch %s should be invalid", patch)
+	}
+}
+
+func TestJSONPointer(t *testing.T) {
+	ut.Equal(t, JSONPointer(), "")
+	ut.Equal(t, JSONPointer("nodes", "3", "address"), "/nodes/3/address")
+	pointer := JSONPointer("a/b", "m~n")
+	ut.Equal(t, pointer, "/a~1b/m~0n")
+	tokens, err := ParseJSONPointer(pointer)
+	ut.Assert(t, err == nil, "")
+	ut.Equal(t, tokens, []string{"a/b", "m~n"})
+}
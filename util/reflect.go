@@ -3,16 +3,21 @@ package util
 import (
 	"fmt"
 	"reflect"
+	"strings"
+	"time"
 )
 
 type Kind string
 
 const (
-	Int    Kind = "int"
-	Uint   Kind = "uint"
-	Struct Kind = "struct"
-	Bool   Kind = "bool"
-	String Kind = "string"
+	Int      Kind = "int"
+	Uint     Kind = "uint"
+	Float    Kind = "float"
+	Struct   Kind = "struct"
+	Bool     Kind = "bool"
+	String   Kind = "string"
+	Time     Kind = "time"
+	Duration Kind = "duration"
 
 	IntSlice       Kind = "intSlice"
 	UintSlice      Kind = "uintSlice"
@@ -21,8 +26,14 @@ const (
 	StructSlice    Kind = "structSlice"
 	StructPtrSlice Kind = "structPtrSlice"
 
-	StructPtr Kind = "structPtr"
-	BoolPtr   Kind = "boolPtr"
+	StructPtr   Kind = "structPtr"
+	BoolPtr     Kind = "boolPtr"
+	IntPtr      Kind = "intPtr"
+	UintPtr     Kind = "uintPtr"
+	FloatPtr    Kind = "floatPtr"
+	StringPtr   Kind = "stringPtr"
+	TimePtr     Kind = "timePtr"
+	DurationPtr Kind = "durationPtr"
 
 	StringIntMap       Kind = "stringIntMap"
 	StringUintMap      Kind = "stringUintMap"
@@ -31,26 +42,49 @@ const (
 	StringStructPtrMap Kind = "stringStructPtrMap"
 )
 
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+//pointer to primary type, time or duration is inspected as
+//pointer kind of the elem
+var ptrKinds = map[Kind]Kind{
+	Struct:   StructPtr,
+	Bool:     BoolPtr,
+	Int:      IntPtr,
+	Uint:     UintPtr,
+	Float:    FloatPtr,
+	String:   StringPtr,
+	Time:     TimePtr,
+	Duration: DurationPtr,
+}
+
 func Inspect(typ reflect.Type) Kind {
+	if typ == durationType {
+		return Duration
+	}
+
 	k := typ.Kind()
 	switch k {
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 		return Int
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
 		return Uint
+	case reflect.Float32, reflect.Float64:
+		return Float
 	case reflect.String:
 		return String
 	case reflect.Bool:
 		return Bool
 	case reflect.Struct:
+		if typ.ConvertibleTo(timeType) {
+			return Time
+		}
 		return Struct
 	case reflect.Ptr:
-		vk := typ.Elem().Kind()
-		if vk == reflect.Struct {
-			return StructPtr
-		}
-		if vk == reflect.Bool {
-			return BoolPtr
+		if pk, ok := ptrKinds[Inspect(typ.Elem())]; ok {
+			return pk
 		}
 	case reflect.Map:
 		if typ.Key().Kind() == reflect.String {
@@ -96,6 +130,8 @@ func Inspect(typ reflect.Type) Kind {
 					return UintSlice
 				case String:
 					return StringSlice
+				case Bool:
+					return BoolSlice
 				}
 			}
 		}
@@ -110,6 +146,8 @@ func PrimartyKind(k reflect.Kind) (Kind, bool) {
 		return Int, true
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
 		return Uint, true
+	case reflect.Float32, reflect.Float64:
+		return Float, true
 	case reflect.String:
 		return String, true
 	case reflect.Bool:
@@ -118,3 +156,105 @@ func PrimartyKind(k reflect.Kind) (Kind, bool) {
 		return "", false
 	}
 }
+
+//return json name of exported fields of the struct, the value is
+//the index sequence for reflect.Value.FieldByIndex, fields of embedded
+//struct are included
+func JsonFieldIndexes(typ reflect.Type) map[string][]int {
+	indexes := make(map[string][]int)
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return indexes
+	}
+	addJsonFieldIndexes(typ, nil, indexes)
+	return indexes
+}
+
+func addJsonFieldIndexes(typ reflect.Type, parent []int, indexes map[string][]int) {
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+		index := append(append([]int{}, parent...), i)
+		if sf.Anonymous && sf.Type.Kind() == reflect.Struct && strings.Split(sf.Tag.Get("json"), ",")[0] == "" {
+			addJsonFieldIndexes(sf.Type, index, indexes)
+			continue
+		}
+
+		if sf.PkgPath != "" {
+			continue
+		}
+
+		name := JsonFieldName(sf)
+		if name == "-" {
+			continue
+		}
+		//outer field hides the embedded one
+		if old, ok := indexes[name]; ok && len(old) <= len(index) {
+			continue
+		}
+		indexes[name] = index
+	}
+}
+
+func JsonFieldName(sf reflect.StructField) string {
+	name := strings.Split(sf.Tag.Get("json"), ",")[0]
+	if name == "" {
+		return sf.Name
+	}
+	return name
+}
+
+//compare value with int, uint, float, string, bool, time kind
+//nil pointer is smaller than any other value, pointer could be
+//compared with the value it points to
+func CompareValue(a, b reflect.Value) (int, error) {
+	if a.Kind() == reflect.Ptr || b.Kind() == reflect.Ptr {
+		if isNilPtr(a) || isNilPtr(b) {
+			return compareBool(!isNilPtr(a), !isNilPtr(b)), nil
+		}
+		return CompareValue(reflect.Indirect(a), reflect.Indirect(b))
+	}
+
+	if a.Type() != b.Type() {
+		return 0, fmt.Errorf("compare value with different type %v and %v", a.Type(), b.Type())
+	}
+
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return compareOrdered(a.Int() < b.Int(), a.Int() > b.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return compareOrdered(a.Uint() < b.Uint(), a.Uint() > b.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return compareOrdered(a.Float() < b.Float(), a.Float() > b.Float()), nil
+	case reflect.String:
+		return strings.Compare(a.String(), b.String()), nil
+	case reflect.Bool:
+		return compareBool(a.Bool(), b.Bool()), nil
+	case reflect.Struct:
+		if a.Type().ConvertibleTo(timeType) {
+			ta := a.Convert(timeType).Interface().(time.Time)
+			tb := b.Convert(timeType).Interface().(time.Time)
+			return compareOrdered(ta.Before(tb), ta.After(tb)), nil
+		}
+	}
+	return 0, fmt.Errorf("value with type %v isn't comparable", a.Type())
+}
+
+func isNilPtr(v reflect.Value) bool {
+	return v.Kind() == reflect.Ptr && v.IsNil()
+}
+
+func compareOrdered(less, greater bool) int {
+	if less {
+		return -1
+	} else if greater {
+		return 1
+	} else {
+		return 0
+	}
+}
+
+func compareBool(a, b bool) int {
+	return compareOrdered(!a && b, a && !b)
+}
@@ -4,6 +4,7 @@ import (
 	//"fmt"
 	"reflect"
 	"testing"
+	"time"
 
 	ut "github.com/zdnscloud/cement/unittest"
 )
@@ -46,4 +47,73 @@ func TestValueKind(t *testing.T) {
 	ut.Equal(t, StringStructPtrMap, Inspect(reflect.TypeOf(v)))
 	v = map[string]MyFlag{}
 	ut.Equal(t, StringStringMap, Inspect(reflect.TypeOf(v)))
+
+	type MyTime time.Time
+	kinds := map[Kind]interface{}{
+		Float:       float32(1.5),
+		Time:        MyTime{},
+		Duration:    time.Second,
+		BoolSlice:   []bool{},
+		BoolPtr:     new(bool),
+		IntPtr:      new(int),
+		UintPtr:     new(uint8),
+		FloatPtr:    new(float64),
+		StringPtr:   new(MyFlag),
+		TimePtr:     &time.Time{},
+		DurationPtr: new(time.Duration),
+	}
+	for kind, v := range kinds {
+		ut.Equal(t, kind, Inspect(reflect.TypeOf(v)))
+	}
+}
+
+func TestJsonFieldIndexes(t *testing.T) {
+	type Base struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+	type MyStruct struct {
+		Base  `json:",inline"`
+		Name  string `json:"name,omitempty"`
+		Count int
+		Skip  string `json:"-"`
+		inner string
+	}
+
+	indexes := JsonFieldIndexes(reflect.TypeOf(&MyStruct{}))
+	ut.Equal(t, indexes, map[string][]int{
+		"id":    []int{0, 0},
+		"name":  []int{1},
+		"Count": []int{2},
+	})
+}
+
+func TestCompareValue(t *testing.T) {
+	now := time.Now()
+	one := 1
+	cases := []struct {
+		a      interface{}
+		b      interface{}
+		result int
+	}{
+		{int8(1), int8(2), -1},
+		{uint(2), uint(1), 1},
+		{1.5, 1.5, 0},
+		{"b", "a", 1},
+		{false, true, -1},
+		{now, now.Add(time.Second), -1},
+		{&one, (*int)(nil), 1},
+		{&one, 2, -1},
+		{0, (*int)(nil), 1},
+	}
+	for _, tc := range cases {
+		result, err := CompareValue(reflect.ValueOf(tc.a), reflect.ValueOf(tc.b))
+		ut.Assert(t, err == nil, "compare %v failed %v", tc.a, err)
+		ut.Equal(t, result, tc.result)
+	}
+
+	_, err := CompareValue(reflect.ValueOf([]int{}), reflect.ValueOf([]int{}))
+	ut.Assert(t, err != nil, "")
+	_, err = CompareValue(reflect.ValueOf(1), reflect.ValueOf("1"))
+	ut.Assert(t, err != nil, "")
 }
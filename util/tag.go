@@ -0,0 +1,25 @@
+package util
+
+import (
+	"strings"
+)
+
+//rest tags are separated by comma, comma in the tag value like
+//regex=^[a-z]{1\,3}$ should be escaped
+func SplitRestTag(rest string) []string {
+	var tags []string
+	var tag strings.Builder
+	for i := 0; i < len(rest); i++ {
+		switch {
+		case rest[i] == '\\' && i+1 < len(rest) && rest[i+1] == ',':
+			tag.WriteByte(',')
+			i++
+		case rest[i] == ',':
+			tags = append(tags, tag.String())
+			tag.Reset()
+		default:
+			tag.WriteByte(rest[i])
+		}
+	}
+	return append(tags, tag.String())
+}
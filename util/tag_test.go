@@ -0,0 +1,22 @@
+package util
+
+import (
+	"testing"
+
+	ut "github.com/zdnscloud/cement/unittest"
+)
+
+func TestSplitRestTag(t *testing.T) {
+	cases := []struct {
+		rest string
+		tags []string
+	}{
+		{"", []string{""}},
+		{"required=true,min=1", []string{"required=true", "min=1"}},
+		{`regex=^[a-z]{1\,3}$,required=true`, []string{"regex=^[a-z]{1,3}$", "required=true"}},
+		{`regex=a\b`, []string{`regex=a\b`}},
+	}
+	for _, tc := range cases {
+		ut.Equal(t, SplitRestTag(tc.rest), tc.tags)
+	}
+}
@@ -0,0 +1,119 @@
+package gorest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"time"
+
+	goresterr "github.com/zdnscloud/gorest/error"
+	"github.com/zdnscloud/gorest/resource"
+)
+
+//heartbeat is sent to keep the connection alive when there is no event
+var WatchHeartbeatInterval = 30 * time.Second
+
+type eventWriter interface {
+	writeEvent(resource.WatchEvent) error
+	writeHeartbeat() error
+}
+
+//events are sent as server-sent events until the channel is closed
+//or the client disconnects
+func handleWatch(ctx *resource.Context) (*Response, *goresterr.APIError) {
+	handler := ctx.Resource.GetSchema().GetHandler().GetWatchHandler()
+	if handler == nil {
+		return nil, goresterr.NewAPIError(goresterr.NotFound, "no handler for watch")
+	}
+
+	flusher, ok := ctx.Response.(http.Flusher)
+	if !ok {
+		return nil, goresterr.NewAPIError(goresterr.ServerError, "response doesn't support streaming")
+	}
+
+	events, err := handler(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	header := ctx.Response.Header()
+	header.Set(ContentTypeKey, "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	ctx.Response.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	streamEvents(ctx, events, &sseWriter{
+		rw:      ctx.Response,
+		flusher: flusher,
+	})
+	return newStreamedResponse(http.StatusOK), nil
+}
+
+func streamEvents(ctx *resource.Context, events <-chan resource.WatchEvent, w eventWriter) {
+	heartbeat := time.NewTicker(WatchHeartbeatInterval)
+	defer heartbeat.Stop()
+	done := ctx.Request.Context().Done()
+	for {
+		select {
+		case <-done:
+			return
+		case <-heartbeat.C:
+			if err := w.writeHeartbeat(); err != nil {
+				return
+			}
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+			if err := prepareWatchEvent(ctx, &e); err != nil {
+				return
+			}
+			if err := w.writeEvent(e); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func prepareWatchEvent(ctx *resource.Context, e *resource.WatchEvent) error {
+	//events of action have no resource
+	if isNilResource(e.Resource) {
+		e.Resource = nil
+		return nil
+	}
+
+	r := e.Resource
+	schema := ctx.Resource.GetSchema()
+	r.SetSchema(schema)
+	r.SetParent(ctx.Resource.GetParent())
+	r.SetType(ctx.Resource.GetType())
+	httpSchemeAndHost := path.Join(ctx.Request.URL.Scheme, ctx.Request.URL.Host)
+	return schema.AddLinksToResource(r, httpSchemeAndHost)
+}
+
+type sseWriter struct {
+	rw      http.ResponseWriter
+	flusher http.Flusher
+}
+
+func (w *sseWriter) writeEvent(e resource.WatchEvent) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w.rw, "event: %s\ndata: %s\n\n", e.Type, data); err != nil {
+		return err
+	}
+	w.flusher.Flush()
+	return nil
+}
+
+func (w *sseWriter) writeHeartbeat() error {
+	if _, err := fmt.Fprint(w.rw, ": heartbeat\n\n"); err != nil {
+		return err
+	}
+	w.flusher.Flush()
+	return nil
+}
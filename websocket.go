@@ -0,0 +1,194 @@
+package gorest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/gorilla/websocket"
+	goresterr "github.com/zdnscloud/gorest/error"
+	"github.com/zdnscloud/gorest/resource"
+)
+
+const webSocketWriteTimeout = 10 * time.Second
+
+//watch of collection and progress of action could be consumed through
+//websocket on the same path, the input of action is sent as the first
+//message after upgrade, every event is sent as one json message,
+//async action isn't supported since its progress is already streamed
+func (s *Server) serveWebSocket(rw http.ResponseWriter, req *http.Request) {
+	conn, err := s.upgrader.Upgrade(rw, req, nil)
+	if err != nil {
+		//upgrader has replied the error to client
+		return
+	}
+	defer conn.Close()
+	//connection is hijacked, panic is reported through close frame
+	if s.recovery {
+		defer s.recoverWebSocketPanic(conn, req)
+	}
+
+	if action := req.URL.Query().Get(resource.ActionQueryKey); action != "" {
+		_, input, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		req = req.Clone(req.Context())
+		req.Method = http.MethodPost
+		req.Body = ioutil.NopCloser(bytes.NewReader(input))
+	}
+
+	reqCtx, cancel := context.WithCancel(req.Context())
+	defer cancel()
+	req = req.WithContext(reqCtx)
+	ctx, apiErr := resource.NewContext(rw, req, s.Schemas)
+	if apiErr != nil {
+		s.finishWebSocket(conn, resource.NewEmptyContext(rw, req, s.Schemas), newErrorResponse(apiErr), websocket.CloseNormalClosure)
+		return
+	}
+
+	release, ok := s.bindStream(ctx)
+	if !ok {
+		err := goresterr.NewAPIError(goresterr.ServiceUnavailable, "server is shutting down")
+		s.finishWebSocket(conn, ctx, newErrorResponse(err), websocket.CloseGoingAway)
+		return
+	}
+	defer release()
+
+	resp := newStreamedResponse(http.StatusSwitchingProtocols)
+	if events, err := s.openEventStream(ctx); err != nil {
+		resp = newErrorResponse(err)
+	} else {
+		go readWebSocket(conn, cancel)
+		streamEvents(ctx, events, &webSocketWriter{conn: conn})
+	}
+
+	code := websocket.CloseNormalClosure
+	if s.isShutdown() {
+		code = websocket.CloseGoingAway
+	}
+	s.finishWebSocket(conn, ctx, resp, code)
+}
+
+//every websocket goes through the after handlers before it's closed
+func (s *Server) finishWebSocket(conn *websocket.Conn, ctx *resource.Context, resp *Response, code int) {
+	for _, h := range s.afterHandlers {
+		h(ctx, resp)
+	}
+	closeWebSocket(conn, code, resp.Error)
+}
+
+func (s *Server) openEventStream(ctx *resource.Context) (<-chan resource.WatchEvent, *goresterr.APIError) {
+	if err := s.runHandlers(ctx); err != nil {
+		return nil, err
+	}
+
+	if action := ctx.Resource.GetAction(); action != nil {
+		if action.Async {
+			return nil, goresterr.NewAPIError(goresterr.InvalidAction, fmt.Sprintf("async action %s isn't supported by websocket", action.Name))
+		}
+		return s.runActionWithProgress(ctx)
+	}
+
+	if ctx.Method == http.MethodGet && isCollection(ctx.Resource) {
+		handler := ctx.Resource.GetSchema().GetHandler().GetWatchHandler()
+		if handler == nil {
+			return nil, goresterr.NewAPIError(goresterr.NotFound, "no handler for watch")
+		}
+		return handler(ctx)
+	}
+
+	return nil, goresterr.NewAPIError(goresterr.MethodNotAllowed, "websocket only supports watch and action")
+}
+
+//progress reported by action handler is sent as event, then the result or error
+func (s *Server) runActionWithProgress(ctx *resource.Context) (<-chan resource.WatchEvent, *goresterr.APIError) {
+	handler := ctx.Resource.GetSchema().GetHandler().GetActionHandler()
+	if handler == nil {
+		return nil, goresterr.NewAPIError(goresterr.NotFound, "no handler for action")
+	}
+
+	if s.debug {
+		handler = checkActionOutput(handler)
+	}
+
+	events := make(chan resource.WatchEvent)
+	done := ctx.Request.Context().Done()
+	send := func(e resource.WatchEvent) {
+		select {
+		case events <- e:
+		case <-done:
+		}
+	}
+	ctx.SetProgressReporter(func(p resource.Progress) {
+		send(resource.WatchEvent{Type: resource.InProgress, Progress: &p})
+	})
+
+	go func() {
+		defer close(events)
+		if s.recovery {
+			defer func() {
+				if r := recover(); r != nil {
+					s.logger.Error("action %s panic: %v\n%s", ctx.Resource.GetAction().Name, r, debug.Stack())
+					send(resource.WatchEvent{
+						Type:  resource.Failed,
+						Error: goresterr.NewAPIError(goresterr.ServerError, "internal server error"),
+					})
+				}
+			}()
+		}
+
+		result, err := handler(ctx)
+		if err != nil {
+			send(resource.WatchEvent{Type: resource.Failed, Error: err})
+		} else {
+			send(resource.WatchEvent{Type: resource.Finished, Result: result})
+		}
+	}()
+	return events, nil
+}
+
+//client only sends control messages after upgrade, the read loop handles
+//pong and close, and cancels the request once the client is gone
+func readWebSocket(conn *websocket.Conn, cancel context.CancelFunc) {
+	defer cancel()
+	pongWait := 2 * WatchHeartbeatInterval
+	conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+	for {
+		if _, _, err := conn.NextReader(); err != nil {
+			return
+		}
+	}
+}
+
+func closeWebSocket(conn *websocket.Conn, code int, err *goresterr.APIError) {
+	deadline := time.Now().Add(webSocketWriteTimeout)
+	if err != nil {
+		conn.SetWriteDeadline(deadline)
+		conn.WriteJSON(resource.WatchEvent{Type: resource.Failed, Error: err})
+	}
+	conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, ""), deadline)
+}
+
+type webSocketWriter struct {
+	conn *websocket.Conn
+}
+
+func (w *webSocketWriter) writeEvent(e resource.WatchEvent) error {
+	w.conn.SetWriteDeadline(time.Now().Add(webSocketWriteTimeout))
+	if err := w.conn.WriteJSON(e); err != nil {
+		return fmt.Errorf("write event failed:%s", err.Error())
+	}
+	return nil
+}
+
+func (w *webSocketWriter) writeHeartbeat() error {
+	return w.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(webSocketWriteTimeout))
+}